@@ -0,0 +1,78 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/corneliusweig/ketall/cmd/internal"
+	"github.com/corneliusweig/ketall/internal/constants"
+	"github.com/corneliusweig/ketall/internal/options"
+	"github.com/corneliusweig/ketall/internal/restore"
+)
+
+var (
+	restoreOptions = options.NewRestoreOptions()
+)
+
+const (
+	restoreLongDescription = `
+Server-side apply an export back to the cluster
+
+All yaml and json manifests below the given directory are applied in dependency
+order: CRDs, namespaces, RBAC, configuration such as config maps and secrets,
+workloads, and finally custom resources. Custom resources are only applied once
+their CRDs are established. The result is reported for each object.
+`
+	restoreExamples = `
+  Export all resources of the default namespace and restore them
+   $ ketall --namespace=default --export --show-secrets --output-dir=backup
+   $ ketall restore backup
+
+  Check which resources would be restored, without changing the cluster
+   $ ketall restore backup --dry-run=server
+`
+)
+
+var restoreCmd = &cobra.Command{
+	Use:     "restore <dir>",
+	Short:   "Server-side apply an export back to the cluster",
+	Long:    internal.HelpTextMapName(restoreLongDescription),
+	Args:    cobra.ExactArgs(1),
+	Example: internal.HelpTextMapName(restoreExamples),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		restoreOptions.Dir = args[0]
+		restoreOptions.Streams = ketallOptions.Streams
+		cmd.SilenceUsage = true
+		return restore.Restore(context.Background(), restoreOptions)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringVar(&restoreOptions.DryRun, constants.FlagDryRun, options.DryRunNone, "Must be \"none\" or \"server\". If server, submit the manifests with server-side dry run, without persisting them.")
+	restoreCmd.Flags().Lookup(constants.FlagDryRun).NoOptDefVal = options.DryRunServer
+	restoreCmd.Flags().StringVar(&restoreOptions.FieldManager, constants.FlagFieldManager, "ketall", "Name of the field manager which owns the applied fields.")
+	restoreCmd.Flags().BoolVar(&restoreOptions.ForceConflicts, constants.FlagForceConflicts, false, "Take ownership of fields which are managed by other field managers.")
+	restoreCmd.Flags().DurationVar(&restoreOptions.Timeout, constants.FlagTimeout, time.Minute, "How long to wait for CRDs to be established before applying their custom resources.")
+
+	restoreOptions.GenericCliFlags.AddFlags(restoreCmd.Flags())
+}
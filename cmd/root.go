@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"flag"
 	"path/filepath"
 
@@ -61,6 +62,27 @@ More on https://github.com/corneliusweig/ketall/blob/v1.3.8/doc/USAGE.md#usage
   Get all cluster level resources
    $ ketall --only-scope=cluster
 
+  Get all unhealthy resources in the default namespace
+   $ ketall --unhealthy --namespace=default
+
+  Show all resources in the default namespace along their owner references
+   $ ketall --namespace=default -o tree
+
+  Count all resources in the default namespace by kind, with the age of the oldest and newest
+   $ ketall --namespace=default --summary=kind --summary-ages
+
+  Report all resources which are stuck in deletion for more than 10 minutes
+   $ ketall --stuck-for 10m -o terminating
+
+  Get all resources which were not created or updated by argocd
+   $ ketall --not-managed-by=argocd-controller --show-managers
+
+  Print nothing when some resources cannot be fetched, for example due to missing permissions
+   $ ketall --fail-on-incomplete
+
+  Get all resources with the options of the presets 'noise' and 'audit' from the config file
+   $ ketall --preset noise,audit
+
   Some options can also be configured in the config file './ketall.yaml' or '~/.kube/ketall.yaml'
 `
 )
@@ -71,32 +93,75 @@ var rootCmd = &cobra.Command{
 	Long:    internal.HelpTextMapName(ketallLongDescription),
 	Args:    cobra.NoArgs,
 	Example: internal.HelpTextMapName(ketallExamples),
-	Run: func(cmd *cobra.Command, args []string) {
-		ketall.KetAll(ketallOptions)
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return applyConfig(cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		err := ketall.KetAll(ketallOptions)
+		if errors.Is(err, ketall.ErrNothingFound) {
+			// "No resources found." was already printed
+			cmd.SilenceErrors = true
+		}
+		return err
 	},
 }
 
+// Exit codes of the ketall command
+const (
+	// ExitComplete means that all resources were fetched and printed
+	ExitComplete = 0
+	// ExitError means that the command failed
+	ExitError = 1
+	// ExitIncomplete means that some resources could not be fetched, so that the printed results are partial
+	ExitIncomplete = 2
+	// ExitNothingFound means that no resources passed the filters
+	ExitNothingFound = 3
+)
+
 func Execute() error {
 	rootCmd.SetOut(ketallOptions.Streams.Out)
 	rootCmd.SetErr(ketallOptions.Streams.ErrOut)
 	return rootCmd.Execute()
 }
 
+// ExitCode returns the exit code for the error returned by Execute.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitComplete
+	case errors.Is(err, ketall.ErrIncomplete):
+		return ExitIncomplete
+	case errors.Is(err, ketall.ErrNothingFound):
+		return ExitNothingFound
+	default:
+		return ExitError
+	}
+}
+
 func init() {
 	klog.InitFlags(flag.CommandLine)
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 	rootCmd.PersistentFlags().StringVar(&ketallOptions.CfgFile, "config", "", "Config file (default \"$HOME/.kube/ketall.yaml)\"")
+	rootCmd.Flags().StringSliceVar(&ketallOptions.Presets, constants.FlagPreset, nil, "Apply the named presets from the config file. Several presets are applied in order, flags given on the command line take precedence.")
 
 	rootCmd.Flags().BoolVar(&ketallOptions.UseCache, constants.FlagUseCache, false, "Use cached list of server resources.")
 	rootCmd.Flags().BoolVar(&ketallOptions.AllowIncomplete, constants.FlagAllowIncomplete, true, "Show partial results when fetching of API resources fails.")
+	rootCmd.Flags().BoolVar(&ketallOptions.FailOnIncomplete, constants.FlagFailOnIncomplete, false, "Print nothing when some resources could not be fetched, for example due to missing permissions. Without this flag, the partial results are printed. The exit code is 2 in both cases.")
 	rootCmd.Flags().StringVar(&ketallOptions.Scope, constants.FlagScope, "", "Only resources with scope cluster|namespace.")
 	rootCmd.Flags().StringVar(&ketallOptions.Since, constants.FlagSince, "", "Only resources younger than given age.")
 	rootCmd.Flags().StringVarP(&ketallOptions.Selector, constants.FlagSelector, "l", "", "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2).")
 	rootCmd.Flags().StringVar(&ketallOptions.FieldSelector, constants.FlagFieldSelector, "", "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The common field queries for all types are metadata.name and metadata.namespace.")
+	rootCmd.Flags().StringVar(&ketallOptions.AnnotationSelector, constants.FlagAnnotationSelector, "", "Selector (annotation query) to filter on, supports '=', '==', '!=', 'key' and '!key'.(e.g. --annotation-selector key1=value1,!key2). Annotations are matched client-side.")
 	rootCmd.Flags().StringSliceVar(&ketallOptions.Exclusions, constants.FlagExclude, []string{"Event", "PodMetrics"}, "Filter by resource name (plural form or short name).")
-	rootCmd.Flags().Int64(constants.FlagConcurrency, 64, "Maximum number of inflight requests.")
+	rootCmd.Flags().BoolVar(&ketallOptions.Terminating, constants.FlagTerminating, false, "Only resources which are marked for deletion.")
+	rootCmd.Flags().StringVar(&ketallOptions.StuckFor, constants.FlagStuckFor, "", "Only resources which are marked for deletion for at least the given age.")
+	rootCmd.Flags().BoolVar(&ketallOptions.Unhealthy, constants.FlagUnhealthy, false, "Only resources which report an unhealthy status, such as failing conditions, a Failed or Pending phase, or missing ready replicas.")
+	rootCmd.Flags().StringSliceVar(&ketallOptions.ManagedBy, constants.FlagManagedBy, nil, "Only resources touched by any of the given field managers (e.g. helm,kubectl-client-side-apply).")
+	rootCmd.Flags().StringSliceVar(&ketallOptions.NotManagedBy, constants.FlagNotManagedBy, nil, "Only resources not touched by any of the given field managers (e.g. argocd-controller).")
+	rootCmd.Flags().Int64Var(&ketallOptions.MaxInflight, constants.FlagConcurrency, 64, "Maximum number of inflight requests.")
 
 	ketallOptions.GenericCliFlags.AddFlags(rootCmd.Flags())
 	ketallOptions.PrintFlags.AddFlags(rootCmd)
@@ -107,6 +172,15 @@ func init() {
 	}
 }
 
+// applyConfig sets the flags of the command from the selected presets, and then from the config
+// file and the environment. Flags given on the command line take precedence over both.
+func applyConfig(cmd *cobra.Command) error {
+	if err := options.ApplyPresets(cmd.Flags(), viper.GetStringMap("presets"), viper.GetStringSlice(constants.FlagPreset)); err != nil {
+		return err
+	}
+	return options.ApplyConfig(cmd.Flags(), viper.GetViper())
+}
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	if ketallOptions.CfgFile != "" {
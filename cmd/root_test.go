@@ -17,10 +17,13 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"os"
 	"testing"
 
+	ketall "github.com/corneliusweig/ketall/internal"
 	"github.com/corneliusweig/ketall/internal/options"
+	pkgerrors "github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -75,3 +78,10 @@ func TestMainVersionCommand(t *testing.T) {
 	assert.Contains(t, stdout.String(), "ketall:")
 	assert.Empty(t, stderr.String())
 }
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, ExitComplete, ExitCode(nil))
+	assert.Equal(t, ExitError, ExitCode(errors.New("unknown command")))
+	assert.Equal(t, ExitIncomplete, ExitCode(pkgerrors.Wrap(ketall.ErrIncomplete, "2 resources could not be fetched")))
+	assert.Equal(t, ExitNothingFound, ExitCode(ketall.ErrNothingFound))
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/corneliusweig/ketall/cmd/internal"
+	"github.com/corneliusweig/ketall/internal/ui"
+)
+
+const (
+	uiLongDescription = `
+Browse all resources in an interactive terminal user interface
+
+The resources are fetched and filtered with the same flags as the main command.
+The upper pane lists the resources, the lower pane shows the YAML of the
+selected resource, with secrets redacted unless --show-secrets is given.
+
+Keys:
+  ↑/↓ j/k, PgUp/PgDn, g/G   move the selection
+  /                         search incrementally, enter to keep, esc to clear
+  n                         cycle through the namespaces
+  t                         cycle through the kinds
+  c                         clear all filters
+  enter                     focus the YAML pane, to scroll it
+  r                         fetch the resources again
+  q, ctrl-c                 quit
+`
+	uiExamples = `
+  Browse all resources in the default namespace
+   $ ketall ui --namespace=default
+
+  Browse all resources created in the last hour, with their creation time
+   $ ketall ui --since 1h --timestamps=both
+`
+)
+
+var uiCmd = &cobra.Command{
+	Use:     "ui",
+	Short:   "Browse all resources in an interactive terminal user interface",
+	Long:    internal.HelpTextMapName(uiLongDescription),
+	Args:    cobra.NoArgs,
+	Example: internal.HelpTextMapName(uiExamples),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return applyConfig(cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return ui.Run(ketallOptions)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+
+	// share the flags of the root command, including the presets and the config file settings
+	uiCmd.Flags().AddFlagSet(rootCmd.Flags())
+}
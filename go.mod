@@ -2,18 +2,22 @@ module github.com/corneliusweig/ketall
 
 require (
 	github.com/blang/semver v3.5.1+incompatible
+	github.com/go-logr/logr v1.2.0
 	github.com/gregjones/httpcache v0.0.0-20190611155906-901d90724c79 // indirect
 	github.com/imdario/mergo v0.3.7 // indirect
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/cobra v1.2.1
+	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.14.0
 	github.com/stretchr/testify v1.8.1
 	golang.org/x/sync v0.1.0
+	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
 	k8s.io/api v0.21.2
 	k8s.io/apimachinery v0.21.2
 	k8s.io/cli-runtime v0.21.2
 	k8s.io/client-go v0.21.2
 	k8s.io/klog/v2 v2.80.1
+	sigs.k8s.io/yaml v1.2.0
 )
 
 go 1.16
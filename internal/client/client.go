@@ -24,10 +24,8 @@ import (
 	"sync"
 	"time"
 
-	"github.com/corneliusweig/ketall/internal/constants"
 	"github.com/corneliusweig/ketall/internal/util"
 	"github.com/pkg/errors"
-	"github.com/spf13/viper"
 	"golang.org/x/sync/semaphore"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -36,10 +34,40 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/klog/v2"
 )
 
-var errEmpty = errors.New("no resources found")
+// ErrEmpty is returned by ListAll when no resource could be fetched, either because there are
+// none to fetch, or because every fetch failed. The failed fetches are returned as ResourceErrors.
+var ErrEmpty = errors.New("no resources found")
+
+// Config holds the settings for discovering and fetching resources.
+type Config struct {
+	Namespace       string
+	Scope           string
+	Selector        string
+	FieldSelector   string
+	Exclusions      []string
+	UseCache        bool
+	AllowIncomplete bool
+	MaxInflight     int64
+}
+
+// ResourceError records that a resource or an API group version could not be fetched.
+type ResourceError struct {
+	Resource string
+	Err      error
+}
+
+func (e ResourceError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Resource, e.Err)
+}
+
+func (e ResourceError) Unwrap() error {
+	return e.Err
+}
 
 // groupResource contains the APIGroup and APIResource
 type groupResource struct {
@@ -47,59 +75,101 @@ type groupResource struct {
 	APIResource metav1.APIResource
 }
 
-func GetAllServerResources(flags *genericclioptions.ConfigFlags) (runtime.Object, error) {
-	useCache := viper.GetBool(constants.FlagUseCache)
-	scope := viper.GetString(constants.FlagScope)
+// GetAllServerResources fetches all objects like ListAll, and logs the resources which
+// cannot be fetched as warnings.
+func GetAllServerResources(flags genericclioptions.RESTClientGetter, c Config) (runtime.Object, []ResourceError, error) {
+	response, resourceErrors, err := ListAll(context.TODO(), flags, c)
+	for _, e := range resourceErrors {
+		klog.Warningf("Cannot fetch: %v", e)
+	}
+	return response, resourceErrors, err
+}
 
-	grs, err := groupResources(useCache, scope, flags)
+// ListAll fetches all objects of all resources which can be listed. Resources and API group
+// versions which cannot be fetched are returned as ResourceErrors, unless the failed discovery
+// of an API group version is not allowed by the config.
+//
+// Cancelling ctx stops ListAll between requests, but does not abort a running request yet,
+// because the resource builder does not support contexts. A slow bulk request therefore
+// finishes before the cancellation takes effect.
+func ListAll(ctx context.Context, flags genericclioptions.RESTClientGetter, c Config) (runtime.Object, []ResourceError, error) {
+	grs, resourceErrors, err := groupResources(flags, c)
 	if err != nil {
-		return nil, errors.Wrap(err, "fetch available group resources")
+		return nil, resourceErrors, errors.Wrap(err, "fetch available group resources")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, resourceErrors, err
 	}
 
 	start := time.Now()
-	response, err := fetchResourcesBulk(flags, grs...)
+	response, err := fetchResourcesBulk(flags, c, grs...)
 	klog.V(2).Infof("Initial fetchResourcesBulk done (%s)", duration.HumanDuration(time.Since(start)))
 	if err == nil {
-		return response, nil
+		return response, resourceErrors, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, resourceErrors, err
 	}
 
-	return fetchResourcesIncremental(context.TODO(), flags, grs...)
+	response, fetchErrors, err := fetchResourcesIncremental(ctx, flags, c, grs...)
+	return response, append(resourceErrors, fetchErrors...), err
+}
+
+// NewRESTMapper returns a mapper from kinds to resources, which is backed by the same discovery
+// client as the resource fetching. The mapper can be reset to pick up newly established CRDs.
+func NewRESTMapper(flags *genericclioptions.ConfigFlags, cache bool) (*restmapper.DeferredDiscoveryRESTMapper, error) {
+	client, err := flags.ToDiscoveryClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "discovery client")
+	}
+	if !cache {
+		client.Invalidate()
+	}
+	return restmapper.NewDeferredDiscoveryRESTMapper(client), nil
 }
 
-func getExclusions() []string {
-	exclusions := viper.GetStringSlice(constants.FlagExclude)
+func getExclusions(c Config) []string {
+	// copy, so that concurrent runs never append to the backing array of the caller's slice
+	exclusions := append([]string(nil), c.Exclusions...)
 
 	// This is a workaround for a k8s bug where componentstatus is reported even though the selector does not apply
-	selector := viper.GetString(constants.FlagSelector)
-	fieldSelector := viper.GetString(constants.FlagFieldSelector)
-	if selector != "" || fieldSelector != "" {
+	if c.Selector != "" || c.FieldSelector != "" {
 		exclusions = append(exclusions, "componentstatuses")
 	}
 
 	return exclusions
 }
 
-func groupResources(cache bool, scope string, flags *genericclioptions.ConfigFlags) ([]groupResource, error) {
+func groupResources(flags genericclioptions.RESTClientGetter, c Config) ([]groupResource, []ResourceError, error) {
 	client, err := flags.ToDiscoveryClient()
 	if err != nil {
-		return nil, errors.Wrap(err, "discovery client")
+		return nil, nil, errors.Wrap(err, "discovery client")
 	}
 
-	if !cache {
+	if !c.UseCache {
 		client.Invalidate()
 	}
 
-	scopeCluster, scopeNamespace, err := getResourceScope(scope)
+	scopeCluster, scopeNamespace, err := getResourceScope(c.Scope, c.Namespace)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	var resourceErrors []ResourceError
 	resources, err := client.ServerPreferredResources()
 	if err != nil {
-		if resources == nil || !viper.GetBool(constants.FlagAllowIncomplete) {
-			return nil, errors.Wrap(err, "get preferred resources")
+		if resources == nil || !c.AllowIncomplete {
+			return nil, nil, errors.Wrap(err, "get preferred resources")
 		}
 		klog.Warningf("Could not fetch complete list of API resources, results will be incomplete: %s", err)
+		if failed, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
+			for gv, e := range failed.Groups {
+				resourceErrors = append(resourceErrors, ResourceError{Resource: gv.String(), Err: e})
+			}
+			sort.Slice(resourceErrors, func(i, j int) bool { return resourceErrors[i].Resource < resourceErrors[j].Resource })
+		} else {
+			resourceErrors = append(resourceErrors, ResourceError{Resource: "discovery", Err: err})
+		}
 	}
 
 	var grs []groupResource
@@ -134,7 +204,7 @@ func groupResources(cache bool, scope string, flags *genericclioptions.ConfigFla
 	}
 
 	sort.Stable(sortableGroupResource(grs))
-	blocked := sets.NewString(getExclusions()...)
+	blocked := sets.NewString(getExclusions(c)...)
 
 	ret := grs[:0]
 	for _, r := range grs {
@@ -149,20 +219,20 @@ func groupResources(cache bool, scope string, flags *genericclioptions.ConfigFla
 		}
 		ret = append(ret, r)
 	}
-	return ret, nil
+	return ret, resourceErrors, nil
 }
 
 // Fetches all objects in bulk. This is much faster than incrementally but may fail due to missing rights
-func fetchResourcesBulk(flags resource.RESTClientGetter, grs ...groupResource) (runtime.Object, error) {
+func fetchResourcesBulk(flags resource.RESTClientGetter, c Config, grs ...groupResource) (runtime.Object, error) {
 	var resources []string
 	for _, gr := range grs {
 		resources = append(resources, gr.String())
 	}
 	klog.V(2).Infof("Resources to fetch: %s", resources)
 
-	ns := viper.GetString(constants.FlagNamespace)
-	selector := viper.GetString(constants.FlagSelector)
-	fieldSelector := viper.GetString(constants.FlagFieldSelector)
+	ns := c.Namespace
+	selector := c.Selector
+	fieldSelector := c.FieldSelector
 
 	request := resource.NewBuilder(flags).
 		Unstructured().
@@ -176,16 +246,20 @@ func fetchResourcesBulk(flags resource.RESTClientGetter, grs ...groupResource) (
 }
 
 // Fetches all objects of the given resources one-by-one. This can be used as a fallback when fetchResourcesBulk fails.
-func fetchResourcesIncremental(ctx context.Context, flags resource.RESTClientGetter, grs ...groupResource) (runtime.Object, error) {
+func fetchResourcesIncremental(ctx context.Context, flags resource.RESTClientGetter, c Config, grs ...groupResource) (runtime.Object, []ResourceError, error) {
 	// TODO(corneliusweig): this needs to properly pass ctx around
 	klog.V(2).Info("Fetch resources incrementally")
 	start := time.Now()
 
-	maxInflight := viper.GetInt64(constants.FlagConcurrency)
+	maxInflight := c.MaxInflight
+	if maxInflight <= 0 {
+		maxInflight = 64
+	}
 	sem := semaphore.NewWeighted(maxInflight) // restrict parallelism to 64 inflight requests
 
-	var mu sync.Mutex // mu guards ret
+	var mu sync.Mutex // mu guards ret and resourceErrors
 	var ret []runtime.Object
+	var resourceErrors []ResourceError
 
 	var wg sync.WaitGroup
 	for _, gr := range grs {
@@ -196,31 +270,35 @@ func fetchResourcesIncremental(ctx context.Context, flags resource.RESTClientGet
 				return // context cancelled
 			}
 			defer sem.Release(1)
-			obj, err := fetchResourcesBulk(flags, gr)
+			obj, err := fetchResourcesBulk(flags, c, gr)
+			mu.Lock()
+			defer mu.Unlock()
 			if err != nil {
-				klog.Warningf("Cannot fetch: %v", err)
+				resourceErrors = append(resourceErrors, ResourceError{Resource: gr.String(), Err: err})
 				return
 			}
-			mu.Lock()
 			ret = append(ret, obj)
-			mu.Unlock()
 		}(gr)
 	}
 	wg.Wait()
 	klog.V(2).Infof("Requests done (elapsed %s)", duration.HumanDuration(time.Since(start)))
+	sort.Slice(resourceErrors, func(i, j int) bool { return resourceErrors[i].Resource < resourceErrors[j].Resource })
 
+	if err := ctx.Err(); err != nil {
+		return nil, resourceErrors, err
+	}
 	if len(ret) == 0 {
 		klog.Warningf("No resources found, are you authorized? Try to narrow the scope with --namespace.")
-		return nil, errEmpty
+		return nil, resourceErrors, ErrEmpty
 	}
 
-	return util.ToV1List(ret), nil
+	return util.ToV1List(ret), resourceErrors, nil
 }
 
-func getResourceScope(scope string) (cluster, namespace bool, err error) {
+func getResourceScope(scope, ns string) (cluster, namespace bool, err error) {
 	switch scope {
 	case "":
-		cluster = viper.GetString(constants.FlagNamespace) == ""
+		cluster = ns == ""
 		namespace = true
 	case "namespace":
 		cluster = false
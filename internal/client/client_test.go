@@ -53,7 +53,7 @@ func TestGetResourceScope(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			gotCluster, gotNamespace, gotErr := getResourceScope(test.scope)
+			gotCluster, gotNamespace, gotErr := getResourceScope(test.scope, "")
 
 			if gotCluster != test.wantCluster {
 				t.Fatalf("wrong cluster: got %t, want %t", gotCluster, test.wantNamespace)
@@ -70,3 +70,26 @@ func TestGetResourceScope(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExclusions(t *testing.T) {
+	exclusions := make([]string, 1, 4)
+	exclusions[0] = "events"
+
+	withSelector := getExclusions(Config{Exclusions: exclusions, Selector: "app=web"})
+	withFieldSelector := getExclusions(Config{Exclusions: exclusions, FieldSelector: "metadata.name=web"})
+	withoutSelector := getExclusions(Config{Exclusions: exclusions})
+
+	for _, got := range [][]string{withSelector, withFieldSelector} {
+		if len(got) != 2 || got[0] != "events" || got[1] != "componentstatuses" {
+			t.Errorf("getExclusions() = %v, want [events componentstatuses]", got)
+		}
+	}
+	if len(withoutSelector) != 1 || withoutSelector[0] != "events" {
+		t.Errorf("getExclusions() = %v, want [events]", withoutSelector)
+	}
+
+	withSelector[1] = "changed"
+	if withFieldSelector[1] != "componentstatuses" || exclusions[:2][1] != "" {
+		t.Errorf("getExclusions() shares the backing array of the given exclusions")
+	}
+}
@@ -17,13 +17,41 @@ limitations under the License.
 package constants
 
 const (
-	FlagConcurrency     = "max-inflight"
-	FlagExclude         = "exclude"
-	FlagNamespace       = "namespace"
-	FlagScope           = "only-scope"
-	FlagSince           = "since"
-	FlagUseCache        = "use-cache"
-	FlagAllowIncomplete = "allow-incomplete"
-	FlagSelector        = "selector"
-	FlagFieldSelector   = "field-selector"
+	FlagConcurrency        = "max-inflight"
+	FlagExclude            = "exclude"
+	FlagNamespace          = "namespace"
+	FlagScope              = "only-scope"
+	FlagSince              = "since"
+	FlagUseCache           = "use-cache"
+	FlagAllowIncomplete    = "allow-incomplete"
+	FlagFailOnIncomplete   = "fail-on-incomplete"
+	FlagSelector           = "selector"
+	FlagFieldSelector      = "field-selector"
+	FlagAnnotationSelector = "annotation-selector"
+	FlagManagedBy          = "managed-by"
+	FlagNotManagedBy       = "not-managed-by"
+	FlagShowManagers       = "show-managers"
+	FlagShowLabels         = "show-labels"
+	FlagLabelColumns       = "label-columns"
+	FlagNoHeaders          = "no-headers"
+	FlagTerminating        = "terminating"
+	FlagStuckFor           = "stuck-for"
+	FlagUnhealthy          = "unhealthy"
+	FlagPreset             = "preset"
+	FlagSortBy             = "sort-by"
+	FlagSummary            = "summary"
+	FlagSummaryAges        = "summary-ages"
+	FlagExport             = "export"
+	FlagOutputDir          = "output-dir"
+	FlagOutputLayout       = "output-layout"
+	FlagShowSecrets        = "show-secrets"
+	FlagColor              = "color"
+	FlagTimestamps         = "timestamps"
+	FlagLocalTime          = "local-time"
+	FlagShowLastUpdated    = "show-last-updated"
+	FlagRedact             = "redact"
+	FlagDryRun             = "dry-run"
+	FlagFieldManager       = "field-manager"
+	FlagForceConflicts     = "force-conflicts"
+	FlagTimeout            = "timeout"
 )
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+)
+
+type annotationOperator int
+
+const (
+	annotationEquals annotationOperator = iota
+	annotationNotEquals
+	annotationExists
+	annotationDoesNotExist
+)
+
+type annotationRequirement struct {
+	key      string
+	operator annotationOperator
+	value    string
+}
+
+// AnnotationPredicate matches objects whose annotations satisfy all requirements of the selector.
+// Requirements are comma-separated and support '=', '==', '!=', 'key' (exists) and '!key' (does not exist).
+// Unlike label selectors, annotation values are not restricted, except that they must not contain a comma.
+// The key ends at the first operator, so values may contain '=' and '!='.
+func AnnotationPredicate(selector string) (Predicate, error) {
+	requirements, err := parseAnnotationSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(o runtime.Object) bool {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			klog.Warningf("could not extract object metadata for filter")
+			return true
+		}
+
+		annotations := acc.GetAnnotations()
+		for _, r := range requirements {
+			if !r.matches(annotations) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func parseAnnotationSelector(selector string) ([]annotationRequirement, error) {
+	var requirements []annotationRequirement
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		// The key ends at the first '=' or '!=', so that it never contains '=', and values may
+		// contain '=' and '!='.
+		var r annotationRequirement
+		switch i := strings.Index(term, "="); {
+		case i > 0 && term[i-1] == '!':
+			r = annotationRequirement{key: term[:i-1], operator: annotationNotEquals, value: term[i+1:]}
+		case i >= 0:
+			value := strings.TrimPrefix(term[i+1:], "=")
+			r = annotationRequirement{key: term[:i], operator: annotationEquals, value: value}
+		case strings.HasPrefix(term, "!"):
+			r = annotationRequirement{key: term[1:], operator: annotationDoesNotExist}
+		default:
+			r = annotationRequirement{key: term, operator: annotationExists}
+		}
+
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+		if errs := validation.IsQualifiedName(r.key); len(errs) > 0 {
+			return nil, errors.Errorf("invalid annotation key '%s' in selector: %s", r.key, strings.Join(errs, "; "))
+		}
+		requirements = append(requirements, r)
+	}
+	return requirements, nil
+}
+
+func (r *annotationRequirement) matches(annotations map[string]string) bool {
+	value, ok := annotations[r.key]
+	switch r.operator {
+	case annotationEquals:
+		return ok && value == r.value
+	case annotationNotEquals:
+		return !ok || value != r.value
+	case annotationExists:
+		return ok
+	case annotationDoesNotExist:
+		return !ok
+	}
+	return false
+}
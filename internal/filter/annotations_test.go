@@ -0,0 +1,79 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotationPredicate(t *testing.T) {
+	annotations := map[string]string{
+		"owner":                   "team-a",
+		"example.com/injected-by": "sidecar webhook",
+		"example.com/query":       "a!=b",
+		"example.com/assignment":  "a=b",
+	}
+
+	tests := []struct {
+		name      string
+		selector  string
+		want      bool
+		shouldErr bool
+	}{
+		{name: "empty selector", selector: "", want: true},
+		{name: "equals", selector: "owner=team-a", want: true},
+		{name: "double equals", selector: "owner==team-a", want: true},
+		{name: "equals with other value", selector: "owner=team-b", want: false},
+		{name: "equals value with spaces", selector: "example.com/injected-by=sidecar webhook", want: true},
+		{name: "not equals", selector: "owner!=team-b", want: true},
+		{name: "not equals with same value", selector: "owner!=team-a", want: false},
+		{name: "not equals when missing", selector: "missing!=x", want: true},
+		{name: "exists", selector: "example.com/injected-by", want: true},
+		{name: "exists when missing", selector: "missing", want: false},
+		{name: "does not exist", selector: "!missing", want: true},
+		{name: "does not exist when present", selector: "!owner", want: false},
+		{name: "several requirements", selector: "owner=team-a, !missing,example.com/injected-by", want: true},
+		{name: "several requirements, one fails", selector: "owner=team-a,missing", want: false},
+		{name: "invalid key", selector: "no spaces=x", shouldErr: true},
+		{name: "missing key", selector: "=x", shouldErr: true},
+		{name: "equals value with not equals", selector: "example.com/query=a!=b", want: true},
+		{name: "double equals value with not equals", selector: "example.com/query==a!=b", want: true},
+		{name: "equals value with equals", selector: "example.com/assignment=a=b", want: true},
+		{name: "not equals value with equals", selector: "example.com/assignment!=a=b", want: false},
+		{name: "not equals value with not equals", selector: "example.com/query!=a!=c", want: true},
+		{name: "missing key before not equals", selector: "!=x", shouldErr: true},
+		{name: "does not exist with value", selector: "!owner=team-a", shouldErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			predicate, err := AnnotationPredicate(test.selector)
+			if test.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			o := newFakeObj("o1", time.Now())
+			o.Annotations = annotations
+			assert.Equal(t, test.want, predicate(o))
+		})
+	}
+}
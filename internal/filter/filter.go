@@ -24,9 +24,10 @@ import (
 	"github.com/corneliusweig/ketall/internal/constants"
 	"github.com/corneliusweig/ketall/internal/util"
 	"github.com/pkg/errors"
-	"github.com/spf13/viper"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 )
 
@@ -34,28 +35,76 @@ type Predicate = func(runtime.Object) bool
 
 var (
 	// exposed for testing
-	getSince = getSinceViper
+	now = time.Now
 )
 
-func ApplyFilter(o runtime.Object) runtime.Object {
-	predicates := make([]Predicate, 0, 1)
+// Config holds the settings of the filters, zero values disable the respective filter.
+type Config struct {
+	Since              string
+	AnnotationSelector string
+	Unhealthy          bool
+	Terminating        bool
+	StuckFor           string
+	ManagedBy          []string
+	NotManagedBy       []string
+}
 
-	if since := getSince(); since != "" {
-		klog.V(2).Infof("Found %s argument %s", constants.FlagSince, since)
-		predicate, err := AgePredicate(since)
-		if err != nil {
-			klog.Warningf("%s", errors.Wrapf(err, "skipping age filter"))
+// Predicates returns the predicates for all enabled filters. Filters with invalid settings are
+// reported in the returned error, the predicates of all other filters are returned nonetheless.
+// Callers should not filter with an incomplete set of predicates, because that would show
+// objects which the user meant to exclude.
+func Predicates(c Config) ([]Predicate, error) {
+	var predicates []Predicate
+	var errs []error
+
+	if c.Since != "" {
+		klog.V(2).Infof("Found %s argument %s", constants.FlagSince, c.Since)
+		if predicate, err := AgePredicate(c.Since); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid %s", constants.FlagSince))
+		} else {
+			predicates = append(predicates, predicate)
 		}
-		predicates = append(predicates, predicate)
 	}
 
-	filtered, err := ByPredicates(o, predicates...)
-	if err != nil {
-		klog.Warningf("%s", errors.Wrapf(err, "filtering failed"))
-		return o
+	if c.AnnotationSelector != "" {
+		klog.V(2).Infof("Found %s argument %s", constants.FlagAnnotationSelector, c.AnnotationSelector)
+		if predicate, err := AnnotationPredicate(c.AnnotationSelector); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid %s", constants.FlagAnnotationSelector))
+		} else {
+			predicates = append(predicates, predicate)
+		}
+	}
+
+	if c.Unhealthy {
+		klog.V(2).Infof("Found %s argument", constants.FlagUnhealthy)
+		predicates = append(predicates, UnhealthyPredicate())
+	}
+
+	if c.Terminating {
+		klog.V(2).Infof("Found %s argument", constants.FlagTerminating)
+		predicates = append(predicates, TerminatingPredicate())
+	}
+
+	if c.StuckFor != "" {
+		klog.V(2).Infof("Found %s argument %s", constants.FlagStuckFor, c.StuckFor)
+		if predicate, err := StuckForPredicate(c.StuckFor); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid %s", constants.FlagStuckFor))
+		} else {
+			predicates = append(predicates, predicate)
+		}
+	}
+
+	if len(c.ManagedBy) > 0 {
+		klog.V(2).Infof("Found %s argument %s", constants.FlagManagedBy, c.ManagedBy)
+		predicates = append(predicates, ManagedByPredicate(c.ManagedBy))
+	}
+
+	if len(c.NotManagedBy) > 0 {
+		klog.V(2).Infof("Found %s argument %s", constants.FlagNotManagedBy, c.NotManagedBy)
+		predicates = append(predicates, NotManagedByPredicate(c.NotManagedBy))
 	}
 
-	return filtered
+	return predicates, utilerrors.NewAggregate(errs)
 }
 
 func ByPredicates(o runtime.Object, ps ...Predicate) (runtime.Object, error) {
@@ -91,12 +140,12 @@ func ByPredicates(o runtime.Object, ps ...Predicate) (runtime.Object, error) {
 	return util.ToV1List(items), nil
 }
 
+// AgePredicate matches objects which were created at most the given duration ago.
 func AgePredicate(since string) (Predicate, error) {
 	duration, err := ParseHumanDuration(since)
 	if err != nil {
 		return nil, errors.Wrapf(err, "parse duration %s", since)
 	}
-	sinceTimestamp := time.Now().Add(-duration)
 
 	return func(o runtime.Object) bool {
 		acc, err := meta.Accessor(o)
@@ -105,11 +154,73 @@ func AgePredicate(since string) (Predicate, error) {
 			return true
 		}
 
+		// the cutoff moves with the clock, so that the predicate can be reused
+		sinceTimestamp := now().Add(-duration)
 		creationTimestamp := acc.GetCreationTimestamp().Time
 		return !sinceTimestamp.After(creationTimestamp)
 	}, nil
 }
 
+// TerminatingPredicate matches objects which are marked for deletion.
+func TerminatingPredicate() Predicate {
+	return func(o runtime.Object) bool {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			klog.Warningf("could not extract object metadata for filter")
+			return true
+		}
+
+		return acc.GetDeletionTimestamp() != nil
+	}
+}
+
+// StuckForPredicate matches objects which have been marked for deletion at least the given duration ago.
+func StuckForPredicate(stuckFor string) (Predicate, error) {
+	duration, err := ParseHumanDuration(stuckFor)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse duration %s", stuckFor)
+	}
+
+	return func(o runtime.Object) bool {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			klog.Warningf("could not extract object metadata for filter")
+			return true
+		}
+
+		deletedBefore := now().Add(-duration)
+		deletionTimestamp := acc.GetDeletionTimestamp()
+		return deletionTimestamp != nil && !deletionTimestamp.Time.After(deletedBefore)
+	}, nil
+}
+
+// ManagedByPredicate matches objects which have been touched by any of the given field managers.
+func ManagedByPredicate(managers []string) Predicate {
+	wanted := sets.NewString(managers...)
+	return func(o runtime.Object) bool {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			klog.Warningf("could not extract object metadata for filter")
+			return true
+		}
+
+		return wanted.HasAny(util.FieldManagers(acc)...)
+	}
+}
+
+// NotManagedByPredicate matches objects which have not been touched by any of the given field managers.
+func NotManagedByPredicate(managers []string) Predicate {
+	managedBy := ManagedByPredicate(managers)
+	return func(o runtime.Object) bool {
+		if _, err := meta.Accessor(o); err != nil {
+			klog.Warningf("could not extract object metadata for filter")
+			return true
+		}
+
+		return !managedBy(o)
+	}
+}
+
 func ParseHumanDuration(since string) (time.Duration, error) {
 	matchDuration := regexp.MustCompile(`(\d+y)?(\d+d)?(\d+h)?(\d+m)?(\d+s)?`)
 	allMatches := matchDuration.FindAllStringSubmatch(since, -1)
@@ -141,7 +252,3 @@ func ParseHumanDuration(since string) (time.Duration, error) {
 	}
 	return time.Duration(int64(time.Second) * seconds), nil
 }
-
-func getSinceViper() string {
-	return viper.GetString(constants.FlagSince)
-}
@@ -108,15 +108,11 @@ func TestFilterByPredicate(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			originalSince := getSince
-			getSince = func() string {
-				return test.givenMaxAge
-			}
-			defer func() {
-				getSince = originalSince
-			}()
+			predicates, err := Predicates(Config{Since: test.givenMaxAge})
+			assert.NoError(t, err)
 
-			filtered := ApplyFilter(test.objects)
+			filtered, err := ByPredicates(test.objects, predicates...)
+			assert.NoError(t, err)
 
 			actualObjs, _ := meta.ExtractList(filtered)
 			assert.Equal(t, test.expectedNames, toNames(actualObjs))
@@ -179,3 +175,127 @@ func TestParseHumanDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestManagedByPredicates(t *testing.T) {
+	tests := []struct {
+		name          string
+		managers      []string
+		givenManagers []string
+		wantManaged   bool
+	}{
+		{
+			name:          "no managed fields",
+			givenManagers: []string{"helm"},
+			wantManaged:   false,
+		},
+		{
+			name:          "single matching manager",
+			managers:      []string{"helm"},
+			givenManagers: []string{"helm"},
+			wantManaged:   true,
+		},
+		{
+			name:          "one of several managers matches",
+			managers:      []string{"kube-controller-manager", "kubectl-client-side-apply"},
+			givenManagers: []string{"helm", "kubectl-client-side-apply"},
+			wantManaged:   true,
+		},
+		{
+			name:          "no manager matches",
+			managers:      []string{"argocd-controller"},
+			givenManagers: []string{"helm"},
+			wantManaged:   false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := newFakeObj("o1", time.Now())
+			for _, m := range test.managers {
+				o.ManagedFields = append(o.ManagedFields, metav1.ManagedFieldsEntry{Manager: m})
+			}
+
+			assert.Equal(t, test.wantManaged, ManagedByPredicate(test.givenManagers)(o))
+			assert.Equal(t, !test.wantManaged, NotManagedByPredicate(test.givenManagers)(o))
+		})
+	}
+}
+
+func TestTerminatingPredicates(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name            string
+		deletedAt       *time.Time
+		givenStuckFor   string
+		wantTerminating bool
+		wantStuck       bool
+	}{
+		{
+			name:          "not terminating",
+			givenStuckFor: "10m",
+		},
+		{
+			name:            "terminating for a short time",
+			deletedAt:       &[]time.Time{now.Add(-time.Minute)}[0],
+			givenStuckFor:   "10m",
+			wantTerminating: true,
+		},
+		{
+			name:            "terminating for a long time",
+			deletedAt:       &[]time.Time{now.Add(-time.Hour)}[0],
+			givenStuckFor:   "10m",
+			wantTerminating: true,
+			wantStuck:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := newFakeObj("o1", now.Add(-24*time.Hour))
+			if test.deletedAt != nil {
+				o.DeletionTimestamp = &metav1.Time{Time: *test.deletedAt}
+			}
+
+			stuck, err := StuckForPredicate(test.givenStuckFor)
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.wantTerminating, TerminatingPredicate()(o))
+			assert.Equal(t, test.wantStuck, stuck(o))
+		})
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	predicates, err := Predicates(Config{})
+	assert.NoError(t, err)
+	assert.Empty(t, predicates)
+
+	predicates, err = Predicates(Config{Since: "yesterday", Terminating: true, ManagedBy: []string{"helm"}})
+	assert.Error(t, err)
+	assert.Len(t, predicates, 2)
+	for _, p := range predicates {
+		assert.NotNil(t, p)
+	}
+}
+
+func TestPredicatesMoveWithTheClock(t *testing.T) {
+	start := time.Now()
+	defer func() { now = time.Now }()
+	now = func() time.Time { return start }
+
+	o := newFakeObj("o1", start.Add(-30*time.Minute))
+	o.DeletionTimestamp = &metav1.Time{Time: start.Add(-5 * time.Minute)}
+
+	since, err := AgePredicate("1h")
+	assert.NoError(t, err)
+	stuck, err := StuckForPredicate("10m")
+	assert.NoError(t, err)
+	assert.True(t, since(o))
+	assert.False(t, stuck(o))
+
+	// the same predicates are applied again later
+	now = func() time.Time { return start.Add(time.Hour) }
+	assert.False(t, since(o))
+	assert.True(t, stuck(o))
+}
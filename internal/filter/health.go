@@ -0,0 +1,107 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// conditions which indicate a problem when their status is False
+	positiveConditions = sets.NewString("Ready", "Available", "Progressing")
+	// conditions which indicate a problem when their status is True
+	negativeConditions = sets.NewString("Degraded", "Failed")
+	// status phases which indicate a problem
+	unhealthyPhases = sets.NewString("Failed", "Pending", "Unknown", "Lost")
+	// container waiting reasons which indicate a problem
+	unhealthyWaitingReasons = sets.NewString("CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "CreateContainerConfigError", "InvalidImageName")
+)
+
+// UnhealthyPredicate matches objects which report an unhealthy status. It inspects the generic
+// status.conditions and status.phase fields, so that it also applies to custom resources following
+// the condition conventions, as well as kind-specific fields of the core workloads.
+func UnhealthyPredicate() Predicate {
+	return func(o runtime.Object) bool {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+		if err != nil {
+			klog.Warningf("could not convert object for health filter")
+			return false
+		}
+
+		return isUnhealthy(o.GetObjectKind().GroupVersionKind().GroupKind().String(), content)
+	}
+}
+
+func isUnhealthy(groupKind string, obj map[string]interface{}) bool {
+	phase, _, _ := unstructured.NestedString(obj, "status", "phase")
+	if groupKind == "Pod" && phase == "Succeeded" {
+		// completed pods are not ready, but healthy nevertheless
+		return false
+	}
+	if unhealthyPhases.Has(phase) {
+		return true
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		if positiveConditions.Has(conditionType) && status == "False" {
+			return true
+		}
+		if negativeConditions.Has(conditionType) && status == "True" {
+			return true
+		}
+	}
+
+	switch groupKind {
+	case "Deployment.apps", "StatefulSet.apps", "ReplicaSet.apps":
+		desired, found, _ := unstructured.NestedInt64(obj, "spec", "replicas")
+		if !found {
+			desired = 1
+		}
+		ready, _, _ := unstructured.NestedInt64(obj, "status", "readyReplicas")
+		return ready < desired
+	case "DaemonSet.apps":
+		desired, _, _ := unstructured.NestedInt64(obj, "status", "desiredNumberScheduled")
+		ready, _, _ := unstructured.NestedInt64(obj, "status", "numberReady")
+		return ready < desired
+	case "Pod":
+		for _, field := range []string{"initContainerStatuses", "containerStatuses"} {
+			statuses, _, _ := unstructured.NestedSlice(obj, "status", field)
+			for _, s := range statuses {
+				status, ok := s.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				reason, _, _ := unstructured.NestedString(status, "state", "waiting", "reason")
+				if unhealthyWaitingReasons.Has(reason) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestUnhealthyPredicate(t *testing.T) {
+	tests := []struct {
+		name   string
+		object map[string]interface{}
+		want   bool
+	}{
+		{
+			name: "configmap without status",
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+			},
+		},
+		{
+			name: "running pod",
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"status": map[string]interface{}{
+					"phase":      "Running",
+					"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+				},
+			},
+		},
+		{
+			name: "completed pod",
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"status": map[string]interface{}{
+					"phase":      "Succeeded",
+					"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False"}},
+				},
+			},
+		},
+		{
+			name: "pending pod",
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"status":     map[string]interface{}{"phase": "Pending"},
+			},
+			want: true,
+		},
+		{
+			name: "crash looping pod",
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"status": map[string]interface{}{
+					"phase": "Running",
+					"containerStatuses": []interface{}{
+						map[string]interface{}{"state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}}},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "deployment with missing replicas",
+			object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"spec":       map[string]interface{}{"replicas": int64(3)},
+				"status":     map[string]interface{}{"readyReplicas": int64(2)},
+			},
+			want: true,
+		},
+		{
+			name: "deployment with all replicas",
+			object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"spec":       map[string]interface{}{"replicas": int64(3)},
+				"status":     map[string]interface{}{"readyReplicas": int64(3)},
+			},
+		},
+		{
+			name: "scaled down statefulset",
+			object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"spec":       map[string]interface{}{"replicas": int64(0)},
+			},
+		},
+		{
+			name: "daemonset with missing pods",
+			object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "DaemonSet",
+				"status":     map[string]interface{}{"desiredNumberScheduled": int64(3), "numberReady": int64(1)},
+			},
+			want: true,
+		},
+		{
+			name: "degraded custom resource",
+			object: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "Degraded", "status": "True"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "custom resource which is not ready",
+			object: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "lost persistent volume claim",
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "PersistentVolumeClaim",
+				"status":     map[string]interface{}{"phase": "Lost"},
+			},
+			want: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := &unstructured.Unstructured{Object: test.object}
+			assert.Equal(t, test.want, UnhealthyPredicate()(o))
+		})
+	}
+}
@@ -18,57 +18,98 @@ package internal
 
 import (
 	"io"
-	"text/tabwriter"
 
 	"github.com/corneliusweig/ketall/internal/client"
 	"github.com/corneliusweig/ketall/internal/filter"
 	"github.com/corneliusweig/ketall/internal/options"
 	"github.com/corneliusweig/ketall/internal/printer"
-	"k8s.io/cli-runtime/pkg/printers"
-	"k8s.io/klog/v2"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func KetAll(ketallOptions *options.KetallOptions) {
-	all, err := client.GetAllServerResources(ketallOptions.GenericCliFlags)
+var (
+	// ErrNothingFound is returned when no resources pass the filters.
+	ErrNothingFound = errors.New("no resources found")
+	// ErrIncomplete is returned when some resources could not be fetched.
+	ErrIncomplete = errors.New("results are incomplete")
+)
+
+// KetAll fetches, filters and prints all resources. Invalid filter settings are an error. When
+// some resources could not be fetched, the partial results are printed and ErrIncomplete is
+// returned. With --fail-on-incomplete, partial results are not printed at all.
+func KetAll(ketallOptions *options.KetallOptions) error {
+	sorter, err := printer.NewSorter(ketallOptions.PrintFlags.SortBy)
+	if err != nil {
+		return err
+	}
+
+	predicates, err := filter.Predicates(ketallOptions.FilterConfig())
 	if err != nil {
-		klog.Fatal(err)
+		return err
 	}
 
-	filtered := filter.ApplyFilter(all)
+	all, resourceErrors, err := client.GetAllServerResources(ketallOptions.GenericCliFlags, ketallOptions.ClientConfig())
+	if err != nil && !errors.Is(err, client.ErrEmpty) {
+		return err
+	}
+	if len(resourceErrors) > 0 && ketallOptions.FailOnIncomplete {
+		// partial results are not printed at all
+		return outcome(false, resourceErrors)
+	}
+
+	var filtered runtime.Object
+	if all != nil {
+		if filtered, err = filter.ByPredicates(all, predicates...); err != nil {
+			return errors.Wrap(err, "filter")
+		}
+	}
 
 	out := ketallOptions.Streams.Out
 	if filtered == nil {
 		io.WriteString(out, "No resources found.\n")
-		return
+		return outcome(false, resourceErrors)
+	}
+
+	if filtered, err = sorter.Sort(filtered); err != nil {
+		return err
 	}
 
 	resourcePrinter, err := ketallOptions.PrintFlags.ToPrinter()
 	if err != nil {
-		klog.Fatal(err)
+		return err
 	}
 
-	p := resourcePrinter
-	switch pr := resourcePrinter.(type) {
-	// yaml and json printers should operate on the full tree structure with nested lists
-	case *printers.JSONPrinter:
-		p = printer.NewListAdapterPrinter(pr)
-	case *printers.YAMLPrinter:
-		p = printer.NewListAdapterPrinter(pr)
-	// other printers should flatten the resource list and operate on leaf items
-	case *printer.TablePrinter:
-		klog.V(2).Info("Using tabwriter")
-		tw := tabwriter.NewWriter(out, 4, 4, 2, ' ', 0)
-		defer tw.Flush()
-		out = tw
-		if err := pr.PrintHeader(out); err != nil {
-			klog.Fatal("print header", err)
+	if table, ok := resourcePrinter.(*printer.TablePrinter); ok {
+		table.Color = ketallOptions.PrintFlags.UseColor(out)
+	}
+	p := printer.NewListPrinter(resourcePrinter)
+	if ketallOptions.PrintFlags.Export {
+		p = printer.NewExportAdapterPrinter(p)
+	}
+	if !ketallOptions.PrintFlags.ShowSecrets {
+		if p, err = printer.NewRedactAdapterPrinter(p, ketallOptions.PrintFlags.Redact); err != nil {
+			return err
 		}
-		p = printer.NewFlattenListAdapterPrinter(pr)
-	default:
-		p = printer.NewFlattenListAdapterPrinter(pr)
 	}
 
 	if err = p.PrintObj(filtered, out); err != nil {
-		klog.Fatal(err)
+		return err
+	}
+
+	return outcome(true, resourceErrors)
+}
+
+// outcome returns the error for the results. Incomplete results take precedence, because the
+// resources which could not be fetched might have matched.
+func outcome(found bool, resourceErrors []client.ResourceError) error {
+	if len(resourceErrors) == 1 {
+		return errors.Wrap(ErrIncomplete, "1 resource could not be fetched")
+	}
+	if len(resourceErrors) > 0 {
+		return errors.Wrapf(ErrIncomplete, "%d resources could not be fetched", len(resourceErrors))
+	}
+	if !found {
+		return ErrNothingFound
 	}
+	return nil
 }
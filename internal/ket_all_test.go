@@ -0,0 +1,182 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/client"
+	"github.com/corneliusweig/ketall/internal/options"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOutcome(t *testing.T) {
+	forbidden := []client.ResourceError{{Resource: "secrets", Err: errors.New("forbidden")}}
+
+	tests := []struct {
+		name           string
+		found          bool
+		resourceErrors []client.ResourceError
+		want           error
+	}{
+		{name: "complete", found: true},
+		{name: "nothing found", want: ErrNothingFound},
+		{name: "incomplete", found: true, resourceErrors: forbidden, want: ErrIncomplete},
+		{name: "nothing found but incomplete", resourceErrors: forbidden, want: ErrIncomplete},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := outcome(test.found, test.resourceErrors)
+			if test.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, test.want), "got %v", err)
+			if test.want == ErrIncomplete {
+				assert.False(t, errors.Is(err, ErrNothingFound))
+			}
+		})
+	}
+}
+
+// newFakeAPIServer serves configmaps and secrets, where listing the given resources is forbidden.
+func newFakeAPIServer(forbidden ...string) *httptest.Server {
+	responses := map[string]interface{}{
+		"/api":  metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}, Versions: []string{"v1"}},
+		"/apis": metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList"}},
+		"/api/v1": metav1.APIResourceList{
+			TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList"},
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: []string{"get", "list"}},
+				{Name: "secrets", Namespaced: true, Kind: "Secret", Verbs: []string{"get", "list"}},
+			},
+		},
+		"/api/v1/configmaps": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMapList",
+			"metadata":   map[string]interface{}{"resourceVersion": "1"},
+			"items": []interface{}{map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "web", "namespace": "default", "uid": "1"},
+			}},
+		},
+		"/api/v1/secrets": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "SecretList",
+			"metadata":   map[string]interface{}{"resourceVersion": "1"},
+			"items":      []interface{}{},
+		},
+	}
+	for _, resource := range forbidden {
+		responses["/api/v1/"+resource] = metav1.Status{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+			Status:   metav1.StatusFailure,
+			Reason:   metav1.StatusReasonForbidden,
+			Code:     http.StatusForbidden,
+			Message:  resource + ` is forbidden: User "test" cannot list resource "` + resource + `"`,
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if status, ok := response.(metav1.Status); ok {
+			w.WriteHeader(int(status.Code))
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+}
+
+// setUpTestClient points the options at the given API server, and selects the name output.
+func setUpTestClient(t *testing.T, ketallOptions *options.KetallOptions, server string) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	assert.NoError(t, os.WriteFile(kubeconfig, nil, 0600))
+	cacheDir := filepath.Join(dir, "cache")
+
+	ketallOptions.GenericCliFlags.KubeConfig = &kubeconfig
+	ketallOptions.GenericCliFlags.APIServer = &server
+	ketallOptions.GenericCliFlags.CacheDir = &cacheDir
+	output := "name"
+	ketallOptions.PrintFlags.OutputFormat = &output
+}
+
+func TestKetAll_ExitOutcome(t *testing.T) {
+	tests := []struct {
+		name             string
+		forbidden        []string
+		failOnIncomplete bool
+		want             error
+		wantOutput       string
+	}{
+		{
+			name:       "complete",
+			wantOutput: "configmap/web\n",
+		},
+		{
+			name:       "partial results are printed",
+			forbidden:  []string{"secrets"},
+			want:       ErrIncomplete,
+			wantOutput: "configmap/web\n",
+		},
+		{
+			name:             "partial results are not printed with fail-on-incomplete",
+			forbidden:        []string{"secrets"},
+			failOnIncomplete: true,
+			want:             ErrIncomplete,
+		},
+		{
+			name:       "every fetch failed",
+			forbidden:  []string{"configmaps", "secrets"},
+			want:       ErrIncomplete,
+			wantOutput: "No resources found.\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newFakeAPIServer(test.forbidden...)
+			defer server.Close()
+
+			ketallOptions, _, out, _ := options.NewTestTestCmdOptions()
+			setUpTestClient(t, ketallOptions, server.URL)
+			ketallOptions.FailOnIncomplete = test.failOnIncomplete
+
+			err := KetAll(ketallOptions)
+			if test.want == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, test.want), "got %v", err)
+			}
+			assert.Equal(t, test.wantOutput, out.String())
+		})
+	}
+}
@@ -18,32 +18,49 @@ package options
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"github.com/corneliusweig/ketall/internal/client"
+	"github.com/corneliusweig/ketall/internal/constants"
+	"github.com/corneliusweig/ketall/internal/filter"
 	"github.com/corneliusweig/ketall/internal/printer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/klog/v2"
 )
 
 type KetallOptions struct {
-	CfgFile         string
-	GenericCliFlags *genericclioptions.ConfigFlags
-	PrintFlags      KAPrintFlags
-	UseCache        bool
-	AllowIncomplete bool
-	Scope           string
-	Since           string
-	Selector        string
-	FieldSelector   string
-	Exclusions      []string
-	Streams         *genericclioptions.IOStreams
+	CfgFile            string
+	Presets            []string
+	GenericCliFlags    *genericclioptions.ConfigFlags
+	PrintFlags         KAPrintFlags
+	UseCache           bool
+	AllowIncomplete    bool
+	FailOnIncomplete   bool
+	Scope              string
+	Since              string
+	Selector           string
+	FieldSelector      string
+	AnnotationSelector string
+	Exclusions         []string
+	Terminating        bool
+	StuckFor           string
+	Unhealthy          bool
+	ManagedBy          []string
+	NotManagedBy       []string
+	MaxInflight        int64
+	Streams            *genericclioptions.IOStreams
 }
 
 func NewCmdOptions() *KetallOptions {
 	return &KetallOptions{
 		GenericCliFlags: genericclioptions.NewConfigFlags(true),
-		PrintFlags:      KAPrintFlags{genericclioptions.NewPrintFlags("")},
+		PrintFlags:      NewKAPrintFlags(),
 		Streams: &genericclioptions.IOStreams{
 			In:     os.Stdin,
 			Out:    os.Stdout,
@@ -58,18 +75,291 @@ func NewTestTestCmdOptions() (*KetallOptions, *bytes.Buffer, *bytes.Buffer, *byt
 	klog.SetOutput(errout)
 	return &KetallOptions{
 		GenericCliFlags: genericclioptions.NewConfigFlags(true),
-		PrintFlags:      KAPrintFlags{genericclioptions.NewPrintFlags("")},
+		PrintFlags:      NewKAPrintFlags(),
 		Streams:         &iostreams,
 	}, in, out, errout
 }
 
+// ClientConfig returns the settings for discovering and fetching resources.
+func (o *KetallOptions) ClientConfig() client.Config {
+	var namespace string
+	if o.GenericCliFlags != nil && o.GenericCliFlags.Namespace != nil {
+		namespace = *o.GenericCliFlags.Namespace
+	}
+	return client.Config{
+		Namespace:       namespace,
+		Scope:           o.Scope,
+		Selector:        o.Selector,
+		FieldSelector:   o.FieldSelector,
+		Exclusions:      o.Exclusions,
+		UseCache:        o.UseCache,
+		AllowIncomplete: o.AllowIncomplete,
+		MaxInflight:     o.MaxInflight,
+	}
+}
+
+// FilterConfig returns the settings of the client-side filters.
+func (o *KetallOptions) FilterConfig() filter.Config {
+	return filter.Config{
+		Since:              o.Since,
+		AnnotationSelector: o.AnnotationSelector,
+		Unhealthy:          o.Unhealthy,
+		Terminating:        o.Terminating,
+		StuckFor:           o.StuckFor,
+		ManagedBy:          o.ManagedBy,
+		NotManagedBy:       o.NotManagedBy,
+	}
+}
+
+const (
+	// OutputJSONLines prints one compact JSON object per line
+	OutputJSONLines = "jsonl"
+	// OutputCSV exports the table as comma-separated values
+	OutputCSV = "csv"
+	// OutputTSV exports the table as tab-separated values
+	OutputTSV = "tsv"
+	// OutputHTML renders a self-contained HTML report
+	OutputHTML = "html"
+	// OutputMarkdown renders the table as GitHub-flavored Markdown
+	OutputMarkdown = "markdown"
+	// OutputDOT renders the references between objects as graph in DOT syntax
+	OutputDOT = printer.GraphDOT
+	// OutputMermaid renders the references between objects as graph in Mermaid syntax
+	OutputMermaid = printer.GraphMermaid
+	// OutputTerminating reports terminating objects together with their finalizers
+	OutputTerminating = "terminating"
+	// OutputTree renders objects as a forest along their owner references
+	OutputTree = "tree"
+	// OutputWide adds further metadata columns to the default table
+	OutputWide = "wide"
+	// OutputCustomColumns prints the columns given as <header>:<jsonpath> pairs
+	OutputCustomColumns = "custom-columns"
+	// OutputCustomColumnsFile prints the columns given by a template file
+	OutputCustomColumnsFile = "custom-columns-file"
+)
+
+const (
+	// ColorAuto colors the table output when it is written to a terminal
+	ColorAuto = "auto"
+	// ColorAlways colors the table output
+	ColorAlways = "always"
+	// ColorNever does not color the table output
+	ColorNever = "never"
+)
+
 type KAPrintFlags struct {
 	*genericclioptions.PrintFlags
+	ShowManagers bool
+	ShowLabels   bool
+	LabelColumns []string
+	NoHeaders    bool
+	SortBy       string
+	Summary      string
+	SummaryAges  bool
+	Export       bool
+	OutputDir    string
+	OutputLayout string
+	ShowSecrets  bool
+	Redact       []string
+	Color        string
+	Timestamps   string
+	LocalTime    bool
+	LastUpdated  bool
+}
+
+func NewKAPrintFlags() KAPrintFlags {
+	return KAPrintFlags{PrintFlags: genericclioptions.NewPrintFlags("")}
+}
+
+// AddFlags registers the generic print flags along with the flags specific to the ketall table output.
+func (f *KAPrintFlags) AddFlags(cmd *cobra.Command) {
+	f.PrintFlags.AddFlags(cmd)
+	if output := cmd.Flags().Lookup("output"); output != nil {
+		output.Usage = fmt.Sprintf("Output format. One of: %s.", strings.Join(f.AllowedFormats(), "|"))
+	}
+
+	cmd.Flags().StringVar(&f.SortBy, constants.FlagSortBy, "", "Sort the output by name, namespace, kind, age (youngest first), or a JSONPath expression (e.g. '.metadata.uid'). By default, the output is sorted by API group, kind, namespace and name.")
+	cmd.Flags().BoolVar(&f.ShowManagers, constants.FlagShowManagers, false, "When printing the default table, show the field managers of each object in a MANAGER column.")
+	cmd.Flags().BoolVar(&f.ShowLabels, constants.FlagShowLabels, false, "When printing the default table, show all labels as the last column.")
+	cmd.Flags().StringSliceVarP(&f.LabelColumns, constants.FlagLabelColumns, "L", nil, "When printing the default table, add a column for each of the given labels (e.g. -L key1,key2).")
+	cmd.Flags().BoolVar(&f.NoHeaders, constants.FlagNoHeaders, false, "When printing the default table, csv, tsv or custom-columns, don't print the header line.")
+	cmd.Flags().StringVar(&f.Summary, constants.FlagSummary, "", "Only print the number of resources grouped by kind|namespace|group. Supports the default table and json output.")
+	cmd.Flags().Lookup(constants.FlagSummary).NoOptDefVal = printer.SummaryByKind
+	cmd.Flags().BoolVar(&f.SummaryAges, constants.FlagSummaryAges, false, "When printing a summary, show the ages of the oldest and newest resource of each group.")
+	cmd.Flags().BoolVar(&f.Export, constants.FlagExport, false, "Strip server-populated fields (e.g. uid, resourceVersion, status) and omit controlled objects, so that the output can be applied to a fresh cluster.")
+	cmd.Flags().StringVar(&f.OutputDir, constants.FlagOutputDir, "", "Write each resource to its own file below the given directory and generate a kustomization.yaml per directory. Supports yaml (the default) and json output.")
+	cmd.Flags().StringVar(&f.OutputLayout, constants.FlagOutputLayout, printer.DefaultDirectoryLayout, "When writing to --output-dir, the path of each file as Go template. Available fields are .Namespace, .Group, .Version, .Kind, .Name and .Extension (yaml or json).")
+	cmd.Flags().BoolVar(&f.ShowSecrets, constants.FlagShowSecrets, false, "Print the data of secrets. By default, the values of secrets and of the --redact paths are replaced with a hash.")
+	cmd.Flags().StringSliceVar(&f.Redact, constants.FlagRedact, nil, "Replace the values at the given JSONPaths of all resources with a hash, unless --show-secrets is given (e.g. --redact=.spec.token,.spec.credentials[*].password).")
+	cmd.Flags().StringVar(&f.Color, constants.FlagColor, ColorAuto, "When printing the default table, colorize the output. One of: auto|always|never. With auto, the output is colored if it is a terminal and NO_COLOR is not set.")
+	cmd.Flags().StringVar(&f.Timestamps, constants.FlagTimestamps, printer.TimestampsRelative, "When printing the default table, csv, tsv or markdown, show the age of each resource, its creation time, or both. One of: relative|absolute|both.")
+	cmd.Flags().BoolVar(&f.LocalTime, constants.FlagLocalTime, false, "Show absolute timestamps in the local time zone instead of UTC.")
+	cmd.Flags().BoolVar(&f.LastUpdated, constants.FlagShowLastUpdated, false, "When printing the default table, csv, tsv or markdown, add a LAST-UPDATED column with the latest time any field manager has touched the resource.")
+}
+
+// AllowedFormats returns the output formats of the generic print flags and the ketall specific formats.
+func (f *KAPrintFlags) AllowedFormats() []string {
+	return append(f.PrintFlags.AllowedFormats(), OutputWide, OutputJSONLines, OutputCSV, OutputTSV, OutputHTML, OutputMarkdown, OutputDOT, OutputMermaid, OutputCustomColumns, OutputCustomColumnsFile, OutputTerminating, OutputTree)
 }
 
 func (f *KAPrintFlags) ToPrinter() (printers.ResourcePrinter, error) {
+	switch f.Color {
+	case "", ColorAuto, ColorAlways, ColorNever:
+	default:
+		return nil, fmt.Errorf("%s is not a valid color mode (must be one of '%s', '%s' or '%s')", f.Color, ColorAuto, ColorAlways, ColorNever)
+	}
+	if err := printer.ValidateTimestamps(f.Timestamps); err != nil {
+		return nil, err
+	}
+
+	if f.Summary != "" {
+		return f.toSummaryPrinter()
+	}
+
+	if f.OutputDir != "" {
+		return f.toDirectoryPrinter()
+	}
+
 	if f.OutputFormat == nil || *f.OutputFormat == "" {
-		return &printer.TablePrinter{}, nil
+		return f.toTablePrinter(), nil
+	}
+
+	format, argument := *f.OutputFormat, ""
+	if parts := strings.SplitN(format, "=", 2); len(parts) == 2 {
+		format, argument = parts[0], parts[1]
+	}
+
+	switch format {
+	case OutputCustomColumns:
+		p, err := printer.NewCustomColumnsPrinterFromSpec(argument)
+		if err != nil {
+			return nil, err
+		}
+		p.NoHeaders = f.NoHeaders
+		return p, nil
+	case OutputCustomColumnsFile:
+		file, err := os.Open(argument)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read custom-columns template %s", argument)
+		}
+		defer file.Close()
+		p, err := printer.NewCustomColumnsPrinterFromTemplate(file)
+		if err != nil {
+			return nil, err
+		}
+		p.NoHeaders = f.NoHeaders
+		return p, nil
+	case OutputCSV, OutputTSV:
+		if argument != "" && argument != OutputWide {
+			return nil, fmt.Errorf("unknown %s option %s (must be empty or %s)", format, argument, OutputWide)
+		}
+		p := &printer.CSVPrinter{Table: *f.toTablePrinter(), Separator: ',', NoHeaders: f.NoHeaders}
+		p.Table.Wide = argument == OutputWide
+		if format == OutputTSV {
+			p.Separator = '\t'
+		}
+		return p, nil
+	case OutputWide:
+		p := f.toTablePrinter()
+		p.Wide = true
+		return p, nil
+	case OutputJSONLines:
+		// honor --show-managed-fields in the same way as the json output
+		p, err := f.JSONYamlPrintFlags.ToPrinter("json")
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.(*printers.OmitManagedFieldsPrinter); ok {
+			return &printers.OmitManagedFieldsPrinter{Delegate: &printer.JSONLinesPrinter{}}, nil
+		}
+		return &printer.JSONLinesPrinter{}, nil
+	case OutputHTML:
+		return &printer.HTMLPrinter{}, nil
+	case OutputMarkdown:
+		p := &printer.MarkdownPrinter{Table: *f.toTablePrinter()}
+		for _, option := range strings.Split(argument, ",") {
+			switch option {
+			case "":
+			case OutputWide:
+				p.Table.Wide = true
+			case printer.SummaryByNamespace, printer.SummaryByKind:
+				p.SectionBy = option
+			default:
+				return nil, fmt.Errorf("unknown %s option %s (must be %s, %s or %s)", format, option, OutputWide, printer.SummaryByNamespace, printer.SummaryByKind)
+			}
+		}
+		return p, nil
+	case OutputDOT, OutputMermaid:
+		return &printer.GraphPrinter{Format: format}, nil
+	case OutputTerminating:
+		return &printer.TerminatingPrinter{}, nil
+	case OutputTree:
+		return &printer.TreePrinter{}, nil
 	}
 	return f.PrintFlags.ToPrinter()
 }
+
+// UseColor tells if the table output to the given writer should be colored.
+func (f *KAPrintFlags) UseColor(out io.Writer) bool {
+	switch f.Color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (f *KAPrintFlags) toTablePrinter() *printer.TablePrinter {
+	return &printer.TablePrinter{
+		ShowManagers:    f.ShowManagers,
+		ShowLabels:      f.ShowLabels,
+		LabelColumns:    f.LabelColumns,
+		NoHeaders:       f.NoHeaders,
+		Timestamps:      f.Timestamps,
+		LocalTime:       f.LocalTime,
+		ShowLastUpdated: f.LastUpdated,
+	}
+}
+
+func (f *KAPrintFlags) toSummaryPrinter() (printers.ResourcePrinter, error) {
+	if err := printer.ValidateSummaryGroupBy(f.Summary); err != nil {
+		return nil, err
+	}
+
+	p := &printer.SummaryPrinter{GroupBy: f.Summary, ShowAges: f.SummaryAges}
+	if f.OutputFormat == nil || *f.OutputFormat == "" {
+		return p, nil
+	}
+	if *f.OutputFormat == "json" {
+		p.JSON = true
+		return p, nil
+	}
+	return nil, fmt.Errorf("--%s does not support output format %s (must be the default table or json)", constants.FlagSummary, *f.OutputFormat)
+}
+
+func (f *KAPrintFlags) toDirectoryPrinter() (printers.ResourcePrinter, error) {
+	format := "yaml"
+	if f.OutputFormat != nil && *f.OutputFormat != "" {
+		format = *f.OutputFormat
+	}
+	if format != "yaml" && format != "json" {
+		return nil, fmt.Errorf("--%s does not support output format %s (must be yaml or json)", constants.FlagOutputDir, format)
+	}
+
+	newPrinter := func() (printers.ResourcePrinter, error) {
+		return f.JSONYamlPrintFlags.ToPrinter(format)
+	}
+	if _, err := newPrinter(); err != nil {
+		return nil, err
+	}
+	return printer.NewDirectoryPrinter(f.OutputDir, f.OutputLayout, format, newPrinter)
+}
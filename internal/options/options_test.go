@@ -17,16 +17,17 @@ limitations under the License.
 package options
 
 import (
+	"bytes"
+	"os"
 	"testing"
 
 	"github.com/corneliusweig/ketall/internal/printer"
 	"github.com/stretchr/testify/assert"
-	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 )
 
 func TestKAPrintFlags_ToPrinter(t *testing.T) {
-	flags := KAPrintFlags{genericclioptions.NewPrintFlags("")}
+	flags := NewKAPrintFlags()
 
 	flags.OutputFormat = nil
 	p, err := flags.ToPrinter()
@@ -44,4 +45,123 @@ func TestKAPrintFlags_ToPrinter(t *testing.T) {
 	p, err = flags.ToPrinter()
 	assert.NoError(t, err)
 	assert.IsType(t, &printers.OmitManagedFieldsPrinter{}, p)
+
+	format = OutputTerminating
+	flags.OutputFormat = &format
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.IsType(t, &printer.TerminatingPrinter{}, p)
+
+	format = OutputTree
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.IsType(t, &printer.TreePrinter{}, p)
+
+	format = OutputJSONLines
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.Equal(t, &printers.OmitManagedFieldsPrinter{Delegate: &printer.JSONLinesPrinter{}}, p)
+
+	format = "tsv=wide"
+	flags.NoHeaders = true
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.Equal(t, &printer.CSVPrinter{Table: printer.TablePrinter{Wide: true, NoHeaders: true}, Separator: '\t', NoHeaders: true}, p)
+	flags.NoHeaders = false
+
+	format = "csv=unknown"
+	_, err = flags.ToPrinter()
+	assert.Error(t, err)
+
+	format = OutputHTML
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.IsType(t, &printer.HTMLPrinter{}, p)
+
+	format = OutputMermaid
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.Equal(t, &printer.GraphPrinter{Format: printer.GraphMermaid}, p)
+
+	format = "markdown=kind,wide"
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.Equal(t, &printer.MarkdownPrinter{Table: printer.TablePrinter{Wide: true}, SectionBy: printer.SummaryByKind}, p)
+
+	format = "markdown=group"
+	_, err = flags.ToPrinter()
+	assert.Error(t, err)
+
+	format = "custom-columns=NAME:.metadata.name"
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.IsType(t, &printer.CustomColumnsPrinter{}, p)
+
+	format = "custom-columns-file=does-not-exist"
+	_, err = flags.ToPrinter()
+	assert.Error(t, err)
+
+	flags.Summary = printer.SummaryByNamespace
+	p, err = flags.ToPrinter()
+	assert.Error(t, err)
+
+	format = "json"
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.Equal(t, &printer.SummaryPrinter{GroupBy: printer.SummaryByNamespace, JSON: true}, p)
+
+	flags.Summary = ""
+	format = ""
+	flags.ShowManagers = true
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.Equal(t, &printer.TablePrinter{ShowManagers: true}, p)
+
+	format = OutputWide
+	flags.ShowManagers = false
+	flags.ShowLabels = true
+	flags.LabelColumns = []string{"app"}
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.Equal(t, &printer.TablePrinter{Wide: true, ShowLabels: true, LabelColumns: []string{"app"}}, p)
+
+	flags.ShowLabels = false
+	flags.LabelColumns = nil
+	format = ""
+	flags.OutputDir = "out"
+	p, err = flags.ToPrinter()
+	assert.NoError(t, err)
+	assert.IsType(t, &printer.DirectoryPrinter{}, p)
+
+	flags.OutputLayout = "{{.Namespace"
+	p, err = flags.ToPrinter()
+	assert.Error(t, err)
+
+	flags.OutputLayout = printer.DefaultDirectoryLayout
+	format = OutputTree
+	p, err = flags.ToPrinter()
+	assert.Error(t, err)
+}
+
+func TestKAPrintFlags_UseColor(t *testing.T) {
+	flags := NewKAPrintFlags()
+	out := &bytes.Buffer{}
+
+	flags.Color = ColorAuto
+	assert.False(t, flags.UseColor(out))
+	flags.Color = ColorAlways
+	assert.True(t, flags.UseColor(out))
+	flags.Color = ColorNever
+	assert.False(t, flags.UseColor(os.Stdout))
+
+	defer os.Unsetenv("NO_COLOR")
+	os.Setenv("NO_COLOR", "1")
+	flags.Color = ColorAuto
+	assert.False(t, flags.UseColor(os.Stdout))
+	flags.Color = ColorAlways
+	assert.True(t, flags.UseColor(os.Stdout))
+
+	flags.Color = "sometimes"
+	_, err := flags.ToPrinter()
+	assert.Error(t, err)
 }
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/corneliusweig/ketall/internal/constants"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// ApplyPresets sets the flags from the named presets, in the given order. Presets map flag
+// names to values, for example
+//
+//	presets:
+//	  noise:
+//	    exclude: [Event, PodMetrics, Lease]
+//	    only-scope: namespace
+//
+// Preset names are case-insensitive, like all config keys.
+// Flags which were given explicitly on the command line always take precedence over presets.
+// When several presets set the same list flag, their values are combined.
+func ApplyPresets(flags *pflag.FlagSet, presets map[string]interface{}, names []string) error {
+	explicit := sets.NewString()
+	flags.Visit(func(f *pflag.Flag) {
+		explicit.Insert(f.Name)
+	})
+
+	for _, name := range names {
+		preset, ok := presets[strings.ToLower(name)]
+		if !ok {
+			return errors.Errorf("preset %s is not defined in the config file", name)
+		}
+		settings, ok := preset.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("preset %s must be a map of options", name)
+		}
+
+		keys := make([]string, 0, len(settings))
+		for key := range settings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if key == constants.FlagPreset || flags.Lookup(key) == nil {
+				return errors.Errorf("preset %s: unknown option %s", name, key)
+			}
+			if explicit.Has(key) {
+				klog.V(2).Infof("Preset %s: keeping %s from the command line", name, key)
+				continue
+			}
+			if err := flags.Set(key, presetValue(settings[key])); err != nil {
+				return errors.Wrapf(err, "preset %s: set %s", name, key)
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyConfig sets the flags which were neither given on the command line nor by a preset
+// from the config file or the environment, for example
+//
+//	only-scope: namespace
+//	exclude: [Event, PodMetrics]
+//
+// Keys which do not name a flag are ignored.
+func ApplyConfig(flags *pflag.FlagSet, config *viper.Viper) error {
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed || f.Name == constants.FlagPreset || !config.IsSet(f.Name) {
+			return
+		}
+		if e := flags.Set(f.Name, presetValue(config.Get(f.Name))); e != nil {
+			err = errors.Wrapf(e, "config: set %s", f.Name)
+		}
+	})
+	return err
+}
+
+func presetValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
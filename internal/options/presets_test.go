@@ -0,0 +1,145 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPresets(t *testing.T) {
+	presets := map[string]interface{}{
+		"noise": map[string]interface{}{
+			"exclude":    []interface{}{"Event", "Lease"},
+			"only-scope": "namespace",
+		},
+		"audit": map[string]interface{}{
+			"exclude":   []interface{}{"Secret"},
+			"use-cache": true,
+		},
+		"broken": map[string]interface{}{
+			"no-such-flag": true,
+		},
+		"invalid": "exclude",
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		presets      []string
+		wantExclude  []string
+		wantScope    string
+		wantUseCache bool
+		shouldErr    bool
+	}{
+		{
+			name:        "no presets",
+			wantExclude: []string{"Event", "PodMetrics"},
+		},
+		{
+			name:        "single preset",
+			presets:     []string{"noise"},
+			wantExclude: []string{"Event", "Lease"},
+			wantScope:   "namespace",
+		},
+		{
+			name:         "combined presets",
+			presets:      []string{"noise", "audit"},
+			wantExclude:  []string{"Event", "Lease", "Secret"},
+			wantScope:    "namespace",
+			wantUseCache: true,
+		},
+		{
+			name:        "flags override presets",
+			args:        []string{"--only-scope=cluster", "--exclude=ConfigMap"},
+			presets:     []string{"noise"},
+			wantExclude: []string{"ConfigMap"},
+			wantScope:   "cluster",
+		},
+		{
+			name:      "undefined preset",
+			presets:   []string{"unknown"},
+			shouldErr: true,
+		},
+		{
+			name:      "unknown option",
+			presets:   []string{"broken"},
+			shouldErr: true,
+		},
+		{
+			name:      "preset is no map",
+			presets:   []string{"invalid"},
+			shouldErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var exclude []string
+			var scope string
+			var useCache bool
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.StringSliceVar(&exclude, "exclude", []string{"Event", "PodMetrics"}, "")
+			flags.StringVar(&scope, "only-scope", "", "")
+			flags.BoolVar(&useCache, "use-cache", false, "")
+			assert.NoError(t, flags.Parse(test.args))
+
+			err := ApplyPresets(flags, presets, test.presets)
+			if test.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantExclude, exclude)
+			assert.Equal(t, test.wantScope, scope)
+			assert.Equal(t, test.wantUseCache, useCache)
+		})
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	var exclude []string
+	var scope string
+	var useCache bool
+	var concurrency int64
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringSliceVar(&exclude, "exclude", []string{"Event", "PodMetrics"}, "")
+	flags.StringVar(&scope, "only-scope", "", "")
+	flags.BoolVar(&useCache, "use-cache", false, "")
+	flags.Int64Var(&concurrency, "concurrency", 64, "")
+	assert.NoError(t, flags.Parse([]string{"--only-scope=cluster"}))
+
+	config := viper.New()
+	assert.NoError(t, config.BindPFlags(flags))
+	config.Set("exclude", []interface{}{"Lease", "Secret"})
+	config.Set("only-scope", "namespace")
+	config.Set("use-cache", true)
+	config.Set("unknown", "ignored")
+
+	assert.NoError(t, ApplyConfig(flags, config))
+	assert.Equal(t, []string{"Lease", "Secret"}, exclude)
+	assert.Equal(t, "cluster", scope)
+	assert.True(t, useCache)
+	assert.Equal(t, int64(64), concurrency)
+
+	config.Set("concurrency", "many")
+	assert.Error(t, ApplyConfig(flags, config))
+}
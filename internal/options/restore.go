@@ -0,0 +1,52 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"os"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+const (
+	// DryRunNone applies the manifests
+	DryRunNone = "none"
+	// DryRunServer submits the manifests with server-side dry run
+	DryRunServer = "server"
+)
+
+type RestoreOptions struct {
+	Dir             string
+	DryRun          string
+	FieldManager    string
+	ForceConflicts  bool
+	Timeout         time.Duration
+	GenericCliFlags *genericclioptions.ConfigFlags
+	Streams         *genericclioptions.IOStreams
+}
+
+func NewRestoreOptions() *RestoreOptions {
+	return &RestoreOptions{
+		GenericCliFlags: genericclioptions.NewConfigFlags(true),
+		Streams: &genericclioptions.IOStreams{
+			In:     os.Stdin,
+			Out:    os.Stdout,
+			ErrOut: os.Stderr,
+		},
+	}
+}
@@ -18,6 +18,7 @@ package printer
 
 import (
 	"io"
+	"text/tabwriter"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -69,3 +70,64 @@ func (n *ListAdapterPrinter) PrintObj(r runtime.Object, w io.Writer) error {
 
 	return n.ResourcePrinter.PrintObj(r, w)
 }
+
+// TableAdapter prints the header and all objects of a possibly nested list with a tabwriter.
+type TableAdapter struct {
+	*TablePrinter
+}
+
+func (n *TableAdapter) PrintObj(r runtime.Object, w io.Writer) error {
+	klog.V(2).Info("Using tabwriter")
+	tw := tabwriter.NewWriter(w, 4, 4, 2, ' ', 0)
+	if err := n.PrintHeader(tw); err != nil {
+		return errors.Wrap(err, "print header")
+	}
+	if err := NewFlattenListAdapterPrinter(n.TablePrinter).PrintObj(r, tw); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+// NewListPrinter wraps the printer with the adapter it needs to print a possibly nested list
+// of all objects. This is the single place which decides how a printer sees the list: reports
+// and printers with a single header get the full list of objects, and must therefore not be
+// wrapped by a FlattenListAdapter elsewhere, while the other printers receive one object at a
+// time, so that every object is written as soon as it is processed.
+func NewListPrinter(printer printers.ResourcePrinter) printers.ResourcePrinter {
+	switch pr := printer.(type) {
+	// yaml and json printers should operate on the full tree structure with nested lists
+	case *printers.JSONPrinter, *printers.YAMLPrinter:
+		return NewListAdapterPrinter(pr)
+	// reports and printers with a single header operate on the full list of objects
+	case *TerminatingPrinter, *SummaryPrinter, *TreePrinter, *CustomColumnsPrinter, *CSVPrinter,
+		*HTMLPrinter, *MarkdownPrinter, *GraphPrinter, *DirectoryPrinter:
+		return pr
+	// other printers should flatten the resource list and operate on leaf items
+	case *TablePrinter:
+		return &TableAdapter{pr}
+	default:
+		return NewFlattenListAdapterPrinter(pr)
+	}
+}
+
+// flatten extracts all leaf items from a possibly nested list of objects.
+func flatten(r runtime.Object) ([]runtime.Object, error) {
+	if !meta.IsListType(r) {
+		return []runtime.Object{r}, nil
+	}
+
+	items, err := meta.ExtractList(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "extract resource list")
+	}
+
+	var ret []runtime.Object
+	for _, o := range items {
+		leaves, err := flatten(o)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, leaves...)
+	}
+	return ret, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"hash/fnv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// All color codes have the same length, so that every colored cell is wider by the same amount.
+// This keeps the columns of the tabwriter aligned, although it counts the escape codes as text.
+const (
+	colorNone   = "\x1b[00m"
+	colorBold   = "\x1b[01m"
+	colorDim    = "\x1b[02m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorBright = "\x1b[92m"
+	colorReset  = "\x1b[0m"
+)
+
+// kindColors are assigned to the kinds by their hash, red is reserved for terminating objects.
+var kindColors = []string{
+	"\x1b[32m", "\x1b[33m", "\x1b[34m", "\x1b[35m", "\x1b[36m",
+	"\x1b[92m", "\x1b[93m", "\x1b[94m", "\x1b[95m", "\x1b[96m",
+}
+
+const (
+	// objects younger than freshAge are highlighted
+	freshAge = 10 * time.Minute
+	// objects younger than recentAge are colored
+	recentAge = time.Hour
+)
+
+// colorize wraps each column in an escape code, which is always reset at the end of the column.
+func colorize(color string, columns []string) []string {
+	colored := make([]string, len(columns))
+	for i, column := range columns {
+		colored[i] = color + column + colorReset
+	}
+	return colored
+}
+
+// colorizeRow colors the name by its kind, dims the namespace and colors the age by freshness.
+// Terminating objects are completely red.
+func colorizeRow(groupKind schema.GroupKind, acc metav1.Object, columns []string, now time.Time) []string {
+	if acc.GetDeletionTimestamp() != nil {
+		return colorize(colorRed, columns)
+	}
+
+	colored := colorize(colorNone, columns)
+	colored[0] = kindColor(groupKind) + columns[0] + colorReset
+	colored[1] = colorDim + columns[1] + colorReset
+	colored[2] = ageColor(acc.GetCreationTimestamp(), now) + columns[2] + colorReset
+	return colored
+}
+
+func kindColor(groupKind schema.GroupKind) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(groupKind.String()))
+	return kindColors[h.Sum32()%uint32(len(kindColors))]
+}
+
+func ageColor(created metav1.Time, now time.Time) string {
+	if created.IsZero() {
+		return colorNone
+	}
+	switch age := now.Sub(created.Time); {
+	case age < freshAge:
+		return colorBright
+	case age < recentAge:
+		return colorGreen
+	}
+	return colorNone
+}
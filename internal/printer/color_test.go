@@ -0,0 +1,75 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var escapeCodes = regexp.MustCompile("\x1b\\[[0-9]*m")
+
+func TestTablePrinter_Color(t *testing.T) {
+	old := newUnstructured("apps/v1", "Deployment", "default", "web")
+	old.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-48 * time.Hour)))
+	fresh := newUnstructured("v1", "ConfigMap", "kube-system", "a-much-longer-name")
+	fresh.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-time.Minute)))
+	terminating := newUnstructured("v1", "Namespace", "", "gone")
+	terminating.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-2 * time.Hour)))
+	deleted := metav1.Now()
+	terminating.SetDeletionTimestamp(&deleted)
+	objects := util.ToV1List([]runtime.Object{old, fresh, terminating})
+
+	render := func(color bool) string {
+		buffer := &bytes.Buffer{}
+		tw := tabwriter.NewWriter(buffer, 4, 4, 2, ' ', 0)
+		p := &TablePrinter{Color: color}
+		assert.NoError(t, p.PrintHeader(tw))
+		assert.NoError(t, NewFlattenListAdapterPrinter(p).PrintObj(objects, tw))
+		assert.NoError(t, tw.Flush())
+		return buffer.String()
+	}
+
+	plain, colored := render(false), render(true)
+	assert.NotEqual(t, plain, colored)
+	assert.Equal(t, plain, escapeCodes.ReplaceAllString(colored, ""))
+
+	lines := bytes.Split([]byte(colored), []byte("\n"))
+	assert.Contains(t, string(lines[0]), colorBold+"NAME"+colorReset)
+	assert.Contains(t, string(lines[1]), colorDim+"default"+colorReset)
+	assert.Contains(t, string(lines[1]), colorNone+"2d"+colorReset)
+	assert.Contains(t, string(lines[2]), colorBright+"60s"+colorReset)
+	assert.Contains(t, string(lines[3]), colorRed+"namespace/gone"+colorReset)
+}
+
+func TestKindColor(t *testing.T) {
+	deployment := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	assert.Equal(t, kindColor(deployment), kindColor(deployment))
+	for _, color := range kindColors {
+		assert.Len(t, color, len(colorReset)+1)
+		assert.NotEqual(t, colorRed, color)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"encoding/csv"
+	"io"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// CSVPrinter exports the columns of the table as comma- or tab-separated values. Values which
+// contain the separator, quotes or line breaks are quoted.
+type CSVPrinter struct {
+	// Table selects the columns, including the wide and label columns
+	Table TablePrinter
+	// Separator is the field delimiter, usually ',' or '\t'
+	Separator rune
+	// NoHeaders omits the header line
+	NoHeaders bool
+}
+
+func (p *CSVPrinter) PrintObj(r runtime.Object, w io.Writer) error {
+	items, err := flatten(r)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = p.Separator
+	if !p.NoHeaders {
+		if err := cw.Write(p.Table.header()); err != nil {
+			return err
+		}
+	}
+	for _, o := range items {
+		row, err := p.Table.row(o)
+		if err != nil {
+			return err
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
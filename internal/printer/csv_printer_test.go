@@ -0,0 +1,71 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCSVPrinter_PrintObj(t *testing.T) {
+	deploy := newUnstructured("apps/v1", "Deployment", "default", "web")
+	deploy.SetLabels(map[string]string{"app": "web", "tier": "frontend"})
+	deploy.SetUID("1234")
+	cm := newUnstructured("v1", "ConfigMap", "default", "cfg")
+	cm.SetLabels(map[string]string{"note": `say "hi"`})
+	objects := util.ToV1List([]runtime.Object{deploy, util.ToV1List([]runtime.Object{cm})})
+
+	tests := []struct {
+		name    string
+		printer CSVPrinter
+		want    string
+	}{
+		{
+			name:    "csv with labels",
+			printer: CSVPrinter{Table: TablePrinter{ShowLabels: true}, Separator: ','},
+			want: `NAME,NAMESPACE,AGE,LABELS
+deployment.apps/web,default,<unknown>,"app=web,tier=frontend"
+configmap/cfg,default,<unknown>,"note=say ""hi"""
+`,
+		},
+		{
+			name:    "tsv without headers",
+			printer: CSVPrinter{Table: TablePrinter{LabelColumns: []string{"app"}}, Separator: '\t', NoHeaders: true},
+			want:    "deployment.apps/web\tdefault\t<unknown>\tweb\nconfigmap/cfg\tdefault\t<unknown>\t\n",
+		},
+		{
+			name:    "wide csv",
+			printer: CSVPrinter{Table: TablePrinter{Wide: true}, Separator: ','},
+			want: `NAME,NAMESPACE,AGE,APIVERSION,OWNER,UID,RESOURCEVERSION
+deployment.apps/web,default,<unknown>,apps/v1,<none>,1234,
+configmap/cfg,default,<unknown>,v1,<none>,,
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			assert.NoError(t, test.printer.PrintObj(objects, buffer))
+			assert.Equal(t, test.want, buffer.String())
+		})
+	}
+}
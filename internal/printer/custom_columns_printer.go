@@ -0,0 +1,179 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// customColumn is a single column of the custom-columns output.
+type customColumn struct {
+	header string
+	path   *jsonpath.JSONPath
+}
+
+// CustomColumnsPrinter prints user-defined columns, where each column is given by a JSONPath
+// expression. Because the objects are of many different kinds, paths which do not exist for
+// an object are shown as <none>.
+type CustomColumnsPrinter struct {
+	// NoHeaders omits the header line
+	NoHeaders bool
+	columns   []customColumn
+}
+
+// NewCustomColumnsPrinterFromSpec creates a CustomColumnsPrinter from a comma-separated list of
+// <header>:<jsonpath> pairs, such as 'NAME:.metadata.name,KIND:.kind'.
+func NewCustomColumnsPrinterFromSpec(spec string) (*CustomColumnsPrinter, error) {
+	if spec == "" {
+		return nil, errors.New("custom-columns format specified but no custom columns given")
+	}
+
+	var headers, paths []string
+	for _, part := range strings.Split(spec, ",") {
+		colSpec := strings.SplitN(part, ":", 2)
+		if len(colSpec) != 2 || colSpec[0] == "" || colSpec[1] == "" {
+			return nil, errors.Errorf("unexpected custom-columns spec: %s, expected <header>:<json-path-expr>", part)
+		}
+		headers = append(headers, colSpec[0])
+		paths = append(paths, colSpec[1])
+	}
+	return newCustomColumnsPrinter(headers, paths)
+}
+
+// NewCustomColumnsPrinterFromTemplate creates a CustomColumnsPrinter from a template, where the
+// first line holds the whitespace-separated headers and the second line the JSONPath expressions.
+func NewCustomColumnsPrinterFromTemplate(r io.Reader) (*CustomColumnsPrinter, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, errors.New("invalid template, missing header line. Expected format is one line of space separated headers, one line of space separated column specs.")
+	}
+	headers := strings.Fields(scanner.Text())
+
+	if !scanner.Scan() {
+		return nil, errors.New("invalid template, missing spec line. Expected format is one line of space separated headers, one line of space separated column specs.")
+	}
+	paths := strings.Fields(scanner.Text())
+
+	if len(headers) != len(paths) {
+		return nil, errors.Errorf("number of headers (%d) and column specs (%d) don't match", len(headers), len(paths))
+	}
+	return newCustomColumnsPrinter(headers, paths)
+}
+
+func newCustomColumnsPrinter(headers, paths []string) (*CustomColumnsPrinter, error) {
+	p := &CustomColumnsPrinter{}
+	for i := range headers {
+		parser, err := newJSONPath(headers[i], paths[i])
+		if err != nil {
+			return nil, err
+		}
+		p.columns = append(p.columns, customColumn{header: headers[i], path: parser})
+	}
+	return p, nil
+}
+
+func (p *CustomColumnsPrinter) PrintObj(r runtime.Object, w io.Writer) error {
+	items, err := flatten(r)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 4, 4, 2, ' ', 0)
+	if !p.NoHeaders {
+		headers := make([]string, 0, len(p.columns))
+		for _, c := range p.columns {
+			headers = append(headers, c.header)
+		}
+		if _, err := fmt.Fprintf(tw, "%s\n", strings.Join(headers, "\t")); err != nil {
+			return err
+		}
+	}
+
+	for _, o := range items {
+		content, err := objectContent(o)
+		if err != nil {
+			return err
+		}
+
+		values := make([]string, 0, len(p.columns))
+		for _, c := range p.columns {
+			value, err := columnValue(c.path, content)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		if _, err := fmt.Fprintf(tw, "%s\n", strings.Join(values, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+func columnValue(parser *jsonpath.JSONPath, content map[string]interface{}) (string, error) {
+	results, err := parser.FindResults(content)
+	if err != nil {
+		return "", err
+	}
+
+	var values []string
+	for _, r := range results {
+		for _, v := range r {
+			if !v.IsValid() || !v.CanInterface() {
+				continue
+			}
+			s, err := formatValue(v)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, s)
+		}
+	}
+	if len(values) == 0 {
+		return "<none>", nil
+	}
+	return strings.Join(values, ","), nil
+}
+
+func formatValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "<none>", nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
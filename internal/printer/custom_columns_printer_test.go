@@ -0,0 +1,62 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCustomColumnsPrinter_PrintObj(t *testing.T) {
+	deploy := newUnstructured("apps/v1", "Deployment", "default", "web")
+	deploy.Object["spec"] = map[string]interface{}{"replicas": int64(3)}
+	deploy.SetLabels(map[string]string{"app": "web"})
+	cm := newUnstructured("v1", "ConfigMap", "default", "cfg")
+	cm.Object["data"] = map[string]interface{}{"key": "value"}
+	objects := util.ToV1List([]runtime.Object{deploy, util.ToV1List([]runtime.Object{cm})})
+
+	fromSpec, err := NewCustomColumnsPrinterFromSpec("KIND:.kind,NAME:{.metadata.name},REPLICAS:spec.replicas,DATA:.data")
+	assert.NoError(t, err)
+	fromTemplate, err := NewCustomColumnsPrinterFromTemplate(strings.NewReader("KIND NAME REPLICAS DATA\n.kind {.metadata.name} spec.replicas .data\n"))
+	assert.NoError(t, err)
+
+	for _, p := range []*CustomColumnsPrinter{fromSpec, fromTemplate} {
+		buffer := &bytes.Buffer{}
+		assert.NoError(t, p.PrintObj(objects, buffer))
+		assert.Equal(t, `KIND        NAME  REPLICAS  DATA
+Deployment  web   3         <none>
+ConfigMap   cfg   <none>    {"key":"value"}
+`, buffer.String())
+	}
+}
+
+func TestCustomColumnsPrinter_InvalidSpec(t *testing.T) {
+	for _, spec := range []string{"", "NAME", "NAME:", ":.metadata.name", "NAME:{.metadata.name"} {
+		_, err := NewCustomColumnsPrinterFromSpec(spec)
+		assert.Error(t, err, spec)
+	}
+
+	for _, template := range []string{"", "NAME\n", "NAME KIND\n.metadata.name\n"} {
+		_, err := NewCustomColumnsPrinterFromTemplate(strings.NewReader(template))
+		assert.Error(t, err, template)
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultDirectoryLayout is the default path of each object below the output directory
+	DefaultDirectoryLayout = "{{.Namespace}}/{{.Kind}}-{{.Name}}.{{.Extension}}"
+
+	kustomizationFile = "kustomization.yaml"
+)
+
+// DirectoryPrinter writes each object to its own file below Dir and adds a kustomization.yaml
+// to every directory, which lists the files and subdirectories in that directory. The path of
+// each file is given by a layout template. Paths which are used by several objects get a
+// numeric suffix. The written paths are printed to the given writer.
+type DirectoryPrinter struct {
+	Dir string
+	// NewPrinter returns the printer for a single file. Each file gets its own printer, because
+	// printers such as the YAMLPrinter separate subsequent objects with '---'.
+	NewPrinter func() (printers.ResourcePrinter, error)
+	// Extension is the file extension of the printed format, such as yaml or json
+	Extension string
+	layout    *template.Template
+}
+
+// directoryLayoutData is available in the layout template.
+type directoryLayoutData struct {
+	Namespace, Group, Version, Kind, Name, Extension string
+}
+
+type kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Resources  []string `json:"resources"`
+}
+
+func NewDirectoryPrinter(dir, layout, extension string, newPrinter func() (printers.ResourcePrinter, error)) (*DirectoryPrinter, error) {
+	if layout == "" {
+		layout = DefaultDirectoryLayout
+	}
+	t, err := template.New("layout").Option("missingkey=error").Parse(layout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse output layout %q", layout)
+	}
+	return &DirectoryPrinter{Dir: dir, NewPrinter: newPrinter, Extension: extension, layout: t}, nil
+}
+
+func (p *DirectoryPrinter) PrintObj(r runtime.Object, w io.Writer) error {
+	items, err := flatten(r)
+	if err != nil {
+		return err
+	}
+
+	// resources holds the entries of each directory, relative to Dir
+	resources := map[string]sets.String{".": sets.NewString()}
+	taken := map[string]bool{}
+	for _, o := range items {
+		name, err := p.path(o)
+		if err != nil {
+			return err
+		}
+		name = unique(name, taken)
+		taken[name] = true
+
+		printer, err := p.NewPrinter()
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := printer.PrintObj(o, &buf); err != nil {
+			return err
+		}
+		file := filepath.Join(p.Dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return errors.Wrapf(err, "create directory for %s", file)
+		}
+		if err := ioutil.WriteFile(file, buf.Bytes(), 0644); err != nil {
+			return errors.Wrapf(err, "write %s", file)
+		}
+		if _, err := fmt.Fprintln(w, file); err != nil {
+			return err
+		}
+
+		// register the file in its directory, and each directory in its parent
+		for entry := name; entry != "."; entry = path.Dir(entry) {
+			dir := path.Dir(entry)
+			if resources[dir] == nil {
+				resources[dir] = sets.NewString()
+			}
+			resources[dir].Insert(path.Base(entry))
+		}
+	}
+
+	for dir, entries := range resources {
+		if err := p.writeKustomization(dir, entries.List()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// path renders the layout for the given object into a clean slash-separated path relative to Dir.
+func (p *DirectoryPrinter) path(o runtime.Object) (string, error) {
+	acc, err := meta.Accessor(o)
+	if err != nil {
+		return "", err
+	}
+	gvk := o.GetObjectKind().GroupVersionKind()
+	data := directoryLayoutData{
+		Namespace: acc.GetNamespace(),
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Kind:      gvk.Kind,
+		Name:      acc.GetName(),
+		Extension: p.Extension,
+	}
+
+	var buf bytes.Buffer
+	if err := p.layout.Execute(&buf, data); err != nil {
+		return "", errors.Wrapf(err, "render output layout for %s", fullName(acc.GetName(), gvk.GroupKind()))
+	}
+	name := path.Clean("/" + filepath.ToSlash(buf.String()))[1:]
+	if name == "" || strings.HasSuffix(buf.String(), "/") {
+		return "", fmt.Errorf("output layout for %s does not name a file: %q", fullName(acc.GetName(), gvk.GroupKind()), buf.String())
+	}
+	return name, nil
+}
+
+// unique appends a numeric suffix to the base name of a path until it is not taken.
+// The kustomization files are always taken.
+func unique(name string, taken map[string]bool) string {
+	isTaken := func(n string) bool { return taken[n] || path.Base(n) == kustomizationFile }
+	if !isTaken(name) {
+		return name
+	}
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if !isTaken(candidate) {
+			klog.Warningf("Output path %s is used by several objects, using %s instead", name, candidate)
+			return candidate
+		}
+	}
+}
+
+// writeKustomization writes a kustomization.yaml with the given sorted entries into dir.
+func (p *DirectoryPrinter) writeKustomization(dir string, entries []string) error {
+	content, err := yaml.Marshal(kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  entries,
+	})
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(p.Dir, filepath.FromSlash(dir), kustomizationFile)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return errors.Wrapf(err, "create directory for %s", file)
+	}
+	return errors.Wrapf(ioutil.WriteFile(file, content, 0644), "write %s", file)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+func TestDirectoryPrinter_PrintObj(t *testing.T) {
+	dir := t.TempDir()
+	objects := util.ToV1List([]runtime.Object{
+		newUnstructured("rbac.authorization.k8s.io/v1", "ClusterRole", "", "admin"),
+		util.ToV1List([]runtime.Object{
+			newUnstructured("v1", "ConfigMap", "default", "cfg"),
+			newUnstructured("v1", "Event", "default", "web.1"),
+			newUnstructured("events.k8s.io/v1", "Event", "default", "web.1"),
+		}),
+	})
+
+	newYAMLPrinter := func() (printers.ResourcePrinter, error) { return &printers.YAMLPrinter{}, nil }
+	p, err := NewDirectoryPrinter(dir, "", "yaml", newYAMLPrinter)
+	assert.NoError(t, err)
+	buffer := &bytes.Buffer{}
+	assert.NoError(t, p.PrintObj(objects, buffer))
+	assert.Equal(t, filepath.Join(dir, "ClusterRole-admin.yaml")+"\n"+
+		filepath.Join(dir, "default", "ConfigMap-cfg.yaml")+"\n"+
+		filepath.Join(dir, "default", "Event-web.1.yaml")+"\n"+
+		filepath.Join(dir, "default", "Event-web.1-2.yaml")+"\n", buffer.String())
+
+	// every file holds a single document, also after the first file
+	content, err := ioutil.ReadFile(filepath.Join(dir, "default", "ConfigMap-cfg.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+  namespace: default
+`, string(content))
+
+	root, err := ioutil.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ClusterRole-admin.yaml
+- default
+`, string(root))
+
+	namespaced, err := ioutil.ReadFile(filepath.Join(dir, "default", "kustomization.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ConfigMap-cfg.yaml
+- Event-web.1-2.yaml
+- Event-web.1.yaml
+`, string(namespaced))
+}
+
+func TestDirectoryPrinter_Layout(t *testing.T) {
+	p, err := NewDirectoryPrinter("", "", "json", nil)
+	assert.NoError(t, err)
+	name, err := p.path(newUnstructured("apps/v1", "Deployment", "default", "web"))
+	assert.NoError(t, err)
+	assert.Equal(t, "default/Deployment-web.json", name)
+
+	p, err = NewDirectoryPrinter("", "{{.Group}}/{{.Kind}}/../../../{{.Name}}.txt", "json", nil)
+	assert.NoError(t, err)
+	name, err = p.path(newUnstructured("apps/v1", "Deployment", "default", "web"))
+	assert.NoError(t, err)
+	assert.Equal(t, "web.txt", name)
+
+	p, err = NewDirectoryPrinter("", "{{.Namespace}}/", "yaml", nil)
+	assert.NoError(t, err)
+	_, err = p.path(newUnstructured("apps/v1", "Deployment", "default", "web"))
+	assert.Error(t, err)
+
+	_, err = NewDirectoryPrinter("", "{{.Namespace", "yaml", nil)
+	assert.Error(t, err)
+}
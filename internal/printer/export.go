@@ -0,0 +1,197 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"io"
+	"strings"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/klog/v2"
+)
+
+// set by the endpoints controller on all endpoints it manages
+const endpointsTriggerTimeAnnotation = "endpoints.kubernetes.io/last-change-trigger-time"
+
+var (
+	// metadata fields which are populated by the server
+	serverMetadataFields = []string{
+		"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp",
+		"deletionGracePeriodSeconds", "managedFields", "selfLink", "ownerReferences",
+	}
+	// annotations which are maintained by the server or by clients
+	serverAnnotations = sets.NewString(
+		"kubectl.kubernetes.io/last-applied-configuration",
+		"deployment.kubernetes.io/revision",
+		"control-plane.alpha.kubernetes.io/leader",
+		"pv.kubernetes.io/bind-completed",
+		"pv.kubernetes.io/bound-by-controller",
+		"pv.kubernetes.io/provisioned-by",
+		"volume.beta.kubernetes.io/storage-provisioner",
+		"volume.kubernetes.io/storage-provisioner",
+		"volume.kubernetes.io/selected-node",
+	)
+	// kinds which only reflect the cluster state and cannot be re-applied
+	unexportableKinds = sets.NewString("Event", "Event.events.k8s.io", "ComponentStatus", "Node", "Lease.coordination.k8s.io")
+	// labels which are added to the pod templates of jobs
+	jobControllerLabels = []string{"controller-uid", "job-name", "batch.kubernetes.io/controller-uid", "batch.kubernetes.io/job-name"}
+)
+
+// ExportAdapter removes all fields which are populated by the server, so that the printed
+// objects can be applied to a fresh cluster. Objects which are created by a controller, or
+// which only reflect the cluster state, are omitted.
+type ExportAdapter struct {
+	printers.ResourcePrinter
+}
+
+func NewExportAdapterPrinter(printer printers.ResourcePrinter) printers.ResourcePrinter {
+	klog.V(2).Infof("Wrapping %T with ExportAdapter", printer)
+	return &ExportAdapter{printer}
+}
+
+func (n *ExportAdapter) PrintObj(r runtime.Object, w io.Writer) error {
+	items, err := flatten(r)
+	if err != nil {
+		return err
+	}
+
+	exported := make([]runtime.Object, 0, len(items))
+	for _, o := range items {
+		u, err := toUnstructured(o)
+		if err != nil {
+			return err
+		}
+		if !exportable(u) {
+			klog.V(2).Infof("Skipping %s in export", fullName(u.GetName(), u.GroupVersionKind().GroupKind()))
+			continue
+		}
+		sanitize(u)
+		exported = append(exported, u)
+	}
+
+	return n.ResourcePrinter.PrintObj(util.ToV1List(exported), w)
+}
+
+// toUnstructured returns a deep copy of the object as unstructured object.
+func toUnstructured(o runtime.Object) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o.DeepCopyObject())
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+func exportable(u *unstructured.Unstructured) bool {
+	groupKind := u.GroupVersionKind().GroupKind().String()
+	if unexportableKinds.Has(groupKind) {
+		return false
+	}
+
+	// controlled objects are re-created by their controller
+	for _, ref := range u.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller {
+			return false
+		}
+	}
+
+	switch groupKind {
+	case "Secret":
+		secretType, _, _ := unstructured.NestedString(u.Object, "type")
+		return secretType != "kubernetes.io/service-account-token"
+	case "ConfigMap":
+		// created in every namespace by the root CA publisher
+		return u.GetName() != "kube-root-ca.crt"
+	case "Endpoints":
+		// written by the endpoints controller for services with a selector, without an owner
+		// reference, so that the stale pod IPs must not be exported
+		_, managed := u.GetAnnotations()[endpointsTriggerTimeAnnotation]
+		return !managed
+	}
+	return true
+}
+
+func sanitize(u *unstructured.Unstructured) {
+	for _, field := range serverMetadataFields {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	if u.GetName() != "" {
+		unstructured.RemoveNestedField(u.Object, "metadata", "generateName")
+	}
+
+	annotations := u.GetAnnotations()
+	for key := range annotations {
+		if serverAnnotations.Has(key) {
+			delete(annotations, key)
+		}
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(u.Object, "metadata", "annotations")
+	} else {
+		u.SetAnnotations(annotations)
+	}
+
+	unstructured.RemoveNestedField(u.Object, "status")
+
+	switch u.GroupVersionKind().GroupKind().String() {
+	case "Service":
+		if clusterIP, _, _ := unstructured.NestedString(u.Object, "spec", "clusterIP"); clusterIP != "None" {
+			unstructured.RemoveNestedField(u.Object, "spec", "clusterIP")
+			unstructured.RemoveNestedField(u.Object, "spec", "clusterIPs")
+		}
+		unstructured.RemoveNestedField(u.Object, "spec", "healthCheckNodePort")
+		if ports, found, _ := unstructured.NestedSlice(u.Object, "spec", "ports"); found {
+			for _, p := range maps(ports) {
+				delete(p, "nodePort")
+			}
+			_ = unstructured.SetNestedSlice(u.Object, ports, "spec", "ports")
+		}
+	case "PersistentVolumeClaim":
+		unstructured.RemoveNestedField(u.Object, "spec", "volumeName")
+	case "PersistentVolume":
+		unstructured.RemoveNestedField(u.Object, "spec", "claimRef")
+	case "Pod":
+		unstructured.RemoveNestedField(u.Object, "spec", "nodeName")
+	case "Job.batch":
+		if manual, _, _ := unstructured.NestedBool(u.Object, "spec", "manualSelector"); !manual {
+			unstructured.RemoveNestedField(u.Object, "spec", "selector")
+			for _, label := range jobControllerLabels {
+				unstructured.RemoveNestedField(u.Object, "spec", "template", "metadata", "labels", label)
+			}
+		}
+	case "ServiceAccount":
+		// token secrets are generated for the new service account
+		if secrets, found, _ := unstructured.NestedSlice(u.Object, "secrets"); found {
+			var kept []interface{}
+			for _, s := range maps(secrets) {
+				if name, _, _ := unstructured.NestedString(s, "name"); !strings.HasPrefix(name, u.GetName()+"-token-") {
+					kept = append(kept, s)
+				}
+			}
+			if len(kept) == 0 {
+				unstructured.RemoveNestedField(u.Object, "secrets")
+			} else {
+				_ = unstructured.SetNestedSlice(u.Object, kept, "secrets")
+			}
+		}
+	case "Namespace":
+		unstructured.RemoveNestedField(u.Object, "spec", "finalizers")
+	}
+}
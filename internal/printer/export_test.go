@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestExportAdapter_PrintObj(t *testing.T) {
+	cfg := newUnstructured("v1", "ConfigMap", "default", "cfg")
+	cfg.SetUID("123")
+	cfg.SetResourceVersion("42")
+	cfg.SetCreationTimestamp(metav1.Now())
+	cfg.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl"}})
+	cfg.SetAnnotations(map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"})
+
+	svc := newUnstructured("v1", "Service", "default", "web")
+	_ = unstructured.SetNestedField(svc.Object, "10.0.0.1", "spec", "clusterIP")
+	_ = unstructured.SetNestedSlice(svc.Object, []interface{}{map[string]interface{}{"port": int64(80), "nodePort": int64(30080)}}, "spec", "ports")
+	_ = unstructured.SetNestedField(svc.Object, map[string]interface{}{}, "status", "loadBalancer")
+
+	headless := newUnstructured("v1", "Service", "default", "db")
+	_ = unstructured.SetNestedField(headless.Object, "None", "spec", "clusterIP")
+
+	controlled := newUnstructured("apps/v1", "ReplicaSet", "default", "web-123")
+	isController := true
+	controlled.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: &isController}})
+
+	token := newUnstructured("v1", "Secret", "default", "default-token-abc")
+	_ = unstructured.SetNestedField(token.Object, "kubernetes.io/service-account-token", "type")
+
+	sa := newUnstructured("v1", "ServiceAccount", "default", "default")
+	_ = unstructured.SetNestedSlice(sa.Object, []interface{}{map[string]interface{}{"name": "default-token-abc"}}, "secrets")
+
+	managedEndpoints := newUnstructured("v1", "Endpoints", "default", "web")
+	managedEndpoints.SetAnnotations(map[string]string{"endpoints.kubernetes.io/last-change-trigger-time": "2021-06-01T12:00:00Z"})
+	_ = unstructured.SetNestedSlice(managedEndpoints.Object, []interface{}{map[string]interface{}{"addresses": []interface{}{map[string]interface{}{"ip": "10.1.0.5"}}}}, "subsets")
+
+	manualEndpoints := newUnstructured("v1", "Endpoints", "default", "external-db")
+
+	objects := util.ToV1List([]runtime.Object{
+		managedEndpoints,
+		manualEndpoints,
+		cfg,
+		util.ToV1List([]runtime.Object{svc, headless, controlled}),
+		token,
+		sa,
+		newUnstructured("v1", "Event", "default", "web.1234"),
+	})
+
+	buffer := &bytes.Buffer{}
+	p := NewExportAdapterPrinter(NewFlattenListAdapterPrinter(&JSONLinesPrinter{}))
+	assert.NoError(t, p.PrintObj(objects, buffer))
+	assert.Equal(t, `{"apiVersion":"v1","kind":"Endpoints","metadata":{"name":"external-db","namespace":"default"}}
+{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cfg","namespace":"default"}}
+{"apiVersion":"v1","kind":"Service","metadata":{"name":"web","namespace":"default"},"spec":{"ports":[{"port":80}]}}
+{"apiVersion":"v1","kind":"Service","metadata":{"name":"db","namespace":"default"},"spec":{"clusterIP":"None"}}
+{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"default","namespace":"default"}}
+`, buffer.String())
+
+	// the original objects are not modified
+	assert.Equal(t, "123", string(cfg.GetUID()))
+	clusterIP, _, _ := unstructured.NestedString(svc.Object, "spec", "clusterIP")
+	assert.Equal(t, "10.0.0.1", clusterIP)
+}
@@ -0,0 +1,338 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	GraphDOT     = "dot"
+	GraphMermaid = "mermaid"
+)
+
+// GraphPrinter renders the objects as a directed graph in DOT or Mermaid syntax. Edges follow
+// the owner references, and well-known references between objects: pods to their config maps,
+// secrets, persistent volume claims and service accounts, services to the pods they select,
+// ingresses to their backend services, and role bindings to their roles and subjects.
+// Referenced objects which were not fetched are shown as external nodes.
+type GraphPrinter struct {
+	// Format is one of dot or mermaid
+	Format string
+}
+
+// graphKey identifies an object in the graph.
+type graphKey struct {
+	schema.GroupKind
+	namespace string
+	name      string
+}
+
+type graphNode struct {
+	id       string
+	key      graphKey
+	external bool
+}
+
+type graphEdge struct {
+	from, to *graphNode
+	label    string
+}
+
+type graph struct {
+	nodes []*graphNode
+	edges []graphEdge
+	byKey map[graphKey]*graphNode
+	seen  map[graphEdge]bool
+}
+
+func (p *GraphPrinter) PrintObj(r runtime.Object, w io.Writer) error {
+	items, err := flatten(r)
+	if err != nil {
+		return err
+	}
+
+	g, err := buildGraph(items)
+	if err != nil {
+		return err
+	}
+
+	switch p.Format {
+	case GraphDOT:
+		return g.printDOT(w)
+	case GraphMermaid:
+		return g.printMermaid(w)
+	}
+	return errors.Errorf("%s is not a valid graph format (must be one of '%s' or '%s')", p.Format, GraphDOT, GraphMermaid)
+}
+
+func buildGraph(items []runtime.Object) (*graph, error) {
+	g := &graph{byKey: map[graphKey]*graphNode{}, seen: map[graphEdge]bool{}}
+
+	fetched := make([]*graphNode, 0, len(items))
+	contents := make([]map[string]interface{}, 0, len(items))
+	for _, o := range items {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			return nil, err
+		}
+		content, err := objectContent(o)
+		if err != nil {
+			return nil, err
+		}
+		fetched = append(fetched, g.node(graphKey{GroupKind: getObjectGroupKind(o), namespace: acc.GetNamespace(), name: acc.GetName()}, false))
+		contents = append(contents, content)
+	}
+
+	for i, o := range items {
+		from := fetched[i]
+		content := contents[i]
+
+		acc, _ := meta.Accessor(o)
+		for _, ref := range acc.GetOwnerReferences() {
+			gv, _ := schema.ParseGroupVersion(ref.APIVersion)
+			owner := graphKey{GroupKind: schema.GroupKind{Group: gv.Group, Kind: ref.Kind}, namespace: from.key.namespace, name: ref.Name}
+			if _, ok := g.byKey[owner]; !ok {
+				// namespaced objects may be owned by cluster-scoped objects
+				clusterOwner := owner
+				clusterOwner.namespace = ""
+				if _, ok := g.byKey[clusterOwner]; ok {
+					owner = clusterOwner
+				}
+			}
+			g.edge(g.node(owner, true), from, "owns")
+		}
+
+		switch from.key.GroupKind.String() {
+		case "Pod":
+			g.podReferences(from, content)
+		case "Service":
+			g.serviceReferences(from, content, fetched, contents)
+		case "Ingress.networking.k8s.io", "Ingress.extensions":
+			g.ingressReferences(from, content)
+		case "RoleBinding.rbac.authorization.k8s.io", "ClusterRoleBinding.rbac.authorization.k8s.io":
+			g.bindingReferences(from, content)
+		}
+	}
+	return g, nil
+}
+
+func (g *graph) podReferences(pod *graphNode, content map[string]interface{}) {
+	ref := func(kind, name, label string) {
+		if name != "" {
+			g.edge(pod, g.node(graphKey{GroupKind: schema.GroupKind{Kind: kind}, namespace: pod.key.namespace, name: name}, true), label)
+		}
+	}
+
+	volumes, _, _ := unstructured.NestedSlice(content, "spec", "volumes")
+	for _, v := range maps(volumes) {
+		ref("ConfigMap", nestedString(v, "configMap", "name"), "volume")
+		ref("Secret", nestedString(v, "secret", "secretName"), "volume")
+		ref("PersistentVolumeClaim", nestedString(v, "persistentVolumeClaim", "claimName"), "volume")
+		sources, _, _ := unstructured.NestedSlice(v, "projected", "sources")
+		for _, s := range maps(sources) {
+			ref("ConfigMap", nestedString(s, "configMap", "name"), "volume")
+			ref("Secret", nestedString(s, "secret", "name"), "volume")
+		}
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(content, "spec", field)
+		for _, c := range maps(containers) {
+			envFrom, _, _ := unstructured.NestedSlice(c, "envFrom")
+			for _, e := range maps(envFrom) {
+				ref("ConfigMap", nestedString(e, "configMapRef", "name"), "env")
+				ref("Secret", nestedString(e, "secretRef", "name"), "env")
+			}
+			env, _, _ := unstructured.NestedSlice(c, "env")
+			for _, e := range maps(env) {
+				ref("ConfigMap", nestedString(e, "valueFrom", "configMapKeyRef", "name"), "env")
+				ref("Secret", nestedString(e, "valueFrom", "secretKeyRef", "name"), "env")
+			}
+		}
+	}
+
+	pullSecrets, _, _ := unstructured.NestedSlice(content, "spec", "imagePullSecrets")
+	for _, s := range maps(pullSecrets) {
+		ref("Secret", nestedString(s, "name"), "imagePullSecret")
+	}
+
+	ref("ServiceAccount", nestedString(content, "spec", "serviceAccountName"), "serviceAccount")
+}
+
+func (g *graph) serviceReferences(svc *graphNode, content map[string]interface{}, nodes []*graphNode, contents []map[string]interface{}) {
+	selector, _, _ := unstructured.NestedStringMap(content, "spec", "selector")
+	if len(selector) == 0 {
+		return
+	}
+
+	s := labels.SelectorFromSet(selector)
+	for i, n := range nodes {
+		if n.key.GroupKind.String() != "Pod" || n.key.namespace != svc.key.namespace {
+			continue
+		}
+		podLabels, _, _ := unstructured.NestedStringMap(contents[i], "metadata", "labels")
+		if s.Matches(labels.Set(podLabels)) {
+			g.edge(svc, n, "selects")
+		}
+	}
+}
+
+func (g *graph) ingressReferences(ingress *graphNode, content map[string]interface{}) {
+	ref := func(backend map[string]interface{}) {
+		name := nestedString(backend, "service", "name")
+		if name == "" {
+			name = nestedString(backend, "serviceName")
+		}
+		if name != "" {
+			g.edge(ingress, g.node(graphKey{GroupKind: schema.GroupKind{Kind: "Service"}, namespace: ingress.key.namespace, name: name}, true), "backend")
+		}
+	}
+
+	for _, field := range []string{"defaultBackend", "backend"} {
+		if backend, ok, _ := unstructured.NestedMap(content, "spec", field); ok {
+			ref(backend)
+		}
+	}
+	rules, _, _ := unstructured.NestedSlice(content, "spec", "rules")
+	for _, rule := range maps(rules) {
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+		for _, path := range maps(paths) {
+			if backend, ok, _ := unstructured.NestedMap(path, "backend"); ok {
+				ref(backend)
+			}
+		}
+	}
+}
+
+func (g *graph) bindingReferences(binding *graphNode, content map[string]interface{}) {
+	if kind := nestedString(content, "roleRef", "kind"); kind != "" {
+		namespace := binding.key.namespace
+		if kind == "ClusterRole" {
+			namespace = ""
+		}
+		role := graphKey{GroupKind: schema.GroupKind{Group: nestedString(content, "roleRef", "apiGroup"), Kind: kind}, namespace: namespace, name: nestedString(content, "roleRef", "name")}
+		g.edge(binding, g.node(role, true), "roleRef")
+	}
+
+	subjects, _, _ := unstructured.NestedSlice(content, "subjects")
+	for _, s := range maps(subjects) {
+		kind := nestedString(s, "kind")
+		subject := graphKey{GroupKind: schema.GroupKind{Group: nestedString(s, "apiGroup"), Kind: kind}, name: nestedString(s, "name")}
+		if kind == "ServiceAccount" {
+			subject.namespace = nestedString(s, "namespace")
+			if subject.namespace == "" {
+				subject.namespace = binding.key.namespace
+			}
+		}
+		g.edge(binding, g.node(subject, true), "subject")
+	}
+}
+
+// node returns the node for the given key. If there is none, a new node is added.
+func (g *graph) node(key graphKey, external bool) *graphNode {
+	if n, ok := g.byKey[key]; ok {
+		return n
+	}
+	n := &graphNode{id: fmt.Sprintf("n%d", len(g.nodes)), key: key, external: external}
+	g.nodes = append(g.nodes, n)
+	g.byKey[key] = n
+	return n
+}
+
+func (g *graph) edge(from, to *graphNode, label string) {
+	e := graphEdge{from: from, to: to, label: label}
+	if g.seen[e] {
+		return
+	}
+	g.seen[e] = true
+	g.edges = append(g.edges, e)
+}
+
+func (n *graphNode) label() string {
+	name := fullName(n.key.name, n.key.GroupKind)
+	if n.key.namespace == "" {
+		return name
+	}
+	return fmt.Sprintf("%s\n%s", name, n.key.namespace)
+}
+
+func (g *graph) printDOT(w io.Writer) error {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	var b strings.Builder
+	b.WriteString("digraph ketall {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, n := range g.nodes {
+		style := ""
+		if n.external {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %s [label=\"%s\"%s];\n", n.id, escape.Replace(n.label()), style)
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=\"%s\"];\n", e.from.id, e.to.id, escape.Replace(e.label))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (g *graph) printMermaid(w io.Writer) error {
+	escape := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", "<br/>")
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, n := range g.nodes {
+		class := ""
+		if n.external {
+			class = ":::external"
+		}
+		fmt.Fprintf(&b, "  %s[\"%s\"]%s\n", n.id, escape.Replace(n.label()), class)
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", e.from.id, escape.Replace(e.label), e.to.id)
+	}
+	b.WriteString("  classDef external stroke-dasharray: 5 5\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func maps(items []interface{}) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			ret = append(ret, m)
+		}
+	}
+	return ret
+}
+
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	s, _, _ := unstructured.NestedString(obj, fields...)
+	return s
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func graphTestObjects() runtime.Object {
+	rs := newUnstructured("apps/v1", "ReplicaSet", "default", "web-1")
+
+	pod := newUnstructured("v1", "Pod", "default", "web-1-a")
+	pod.SetLabels(map[string]string{"app": "web"})
+	pod.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-1"}})
+	pod.Object["spec"] = map[string]interface{}{
+		"serviceAccountName": "web",
+		"volumes": []interface{}{
+			map[string]interface{}{"name": "cfg", "configMap": map[string]interface{}{"name": "web-config"}},
+		},
+		"containers": []interface{}{
+			map[string]interface{}{"envFrom": []interface{}{
+				map[string]interface{}{"secretRef": map[string]interface{}{"name": "web-secret"}},
+			}},
+		},
+	}
+
+	cm := newUnstructured("v1", "ConfigMap", "default", "web-config")
+
+	svc := newUnstructured("v1", "Service", "default", "web")
+	svc.Object["spec"] = map[string]interface{}{"selector": map[string]interface{}{"app": "web"}}
+
+	ingress := newUnstructured("networking.k8s.io/v1", "Ingress", "default", "web")
+	ingress.Object["spec"] = map[string]interface{}{"rules": []interface{}{
+		map[string]interface{}{"http": map[string]interface{}{"paths": []interface{}{
+			map[string]interface{}{"backend": map[string]interface{}{"service": map[string]interface{}{"name": "web"}}},
+		}}},
+	}}
+
+	binding := newUnstructured("rbac.authorization.k8s.io/v1", "RoleBinding", "default", "web")
+	binding.Object["roleRef"] = map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": "view"}
+	binding.Object["subjects"] = []interface{}{
+		map[string]interface{}{"kind": "ServiceAccount", "name": "web"},
+		map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": "User", "name": "jane"},
+	}
+
+	return util.ToV1List([]runtime.Object{rs, pod, util.ToV1List([]runtime.Object{cm, svc, ingress}), binding})
+}
+
+func TestGraphPrinter_DOT(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	p := &GraphPrinter{Format: GraphDOT}
+	assert.NoError(t, p.PrintObj(graphTestObjects(), buffer))
+	assert.Equal(t, `digraph ketall {
+  rankdir=LR;
+  node [shape=box];
+  n0 [label="replicaset.apps/web-1\ndefault"];
+  n1 [label="pod/web-1-a\ndefault"];
+  n2 [label="configmap/web-config\ndefault"];
+  n3 [label="service/web\ndefault"];
+  n4 [label="ingress.networking.k8s.io/web\ndefault"];
+  n5 [label="rolebinding.rbac.authorization.k8s.io/web\ndefault"];
+  n6 [label="secret/web-secret\ndefault", style=dashed];
+  n7 [label="serviceaccount/web\ndefault", style=dashed];
+  n8 [label="clusterrole.rbac.authorization.k8s.io/view", style=dashed];
+  n9 [label="user.rbac.authorization.k8s.io/jane", style=dashed];
+  n0 -> n1 [label="owns"];
+  n1 -> n2 [label="volume"];
+  n1 -> n6 [label="env"];
+  n1 -> n7 [label="serviceAccount"];
+  n3 -> n1 [label="selects"];
+  n4 -> n3 [label="backend"];
+  n5 -> n8 [label="roleRef"];
+  n5 -> n7 [label="subject"];
+  n5 -> n9 [label="subject"];
+}
+`, buffer.String())
+}
+
+func TestGraphPrinter_Mermaid(t *testing.T) {
+	objects := util.ToV1List([]runtime.Object{
+		newUnstructured("v1", "Namespace", "", "default"),
+		newUnstructured("v1", "ConfigMap", "default", `say "hi"`),
+	})
+
+	buffer := &bytes.Buffer{}
+	p := &GraphPrinter{Format: GraphMermaid}
+	assert.NoError(t, p.PrintObj(objects, buffer))
+	assert.Equal(t, `graph LR
+  n0["namespace/default"]
+  n1["configmap/say #quot;hi#quot;<br/>default"]
+  classDef external stroke-dasharray: 5 5
+`, buffer.String())
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// HTMLPrinter renders a self-contained HTML report with a summary by namespace and kind, a
+// sortable and filterable table of all objects, and the collapsible YAML of every object.
+type HTMLPrinter struct {
+	// now is exposed for testing
+	now func() time.Time
+}
+
+type htmlReport struct {
+	Generated string
+	Total     int
+	Summaries []*Summary
+	Header    []string
+	Rows      []htmlRow
+}
+
+type htmlRow struct {
+	Columns []string
+	// Created is used to sort by the AGE column
+	Created string
+	YAML    string
+}
+
+func (p *HTMLPrinter) PrintObj(r runtime.Object, w io.Writer) error {
+	items, err := flatten(r)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+	report := htmlReport{Generated: now().UTC().Format(time.RFC3339), Total: len(items)}
+
+	for _, groupBy := range []string{SummaryByNamespace, SummaryByKind} {
+		summary, err := (&SummaryPrinter{GroupBy: groupBy}).summarize(r)
+		if err != nil {
+			return err
+		}
+		report.Summaries = append(report.Summaries, summary)
+	}
+
+	table := &TablePrinter{Wide: true}
+	report.Header = table.header()
+	for _, o := range items {
+		columns, err := table.row(o)
+		if err != nil {
+			return err
+		}
+
+		// like the yaml output, the report omits the managed fields
+		o = o.DeepCopyObject()
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+		acc.SetManagedFields(nil)
+		data, err := yaml.Marshal(o)
+		if err != nil {
+			return errors.Wrap(err, "marshal object")
+		}
+
+		report.Rows = append(report.Rows, htmlRow{
+			Columns: columns,
+			Created: acc.GetCreationTimestamp().UTC().Format(time.RFC3339),
+			YAML:    string(data),
+		})
+	}
+
+	return htmlTemplate.Execute(w, report)
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ketall inventory</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+#objects th { cursor: pointer; }
+.summaries { display: flex; gap: 2em; }
+pre { margin: 0.5em 0 0; }
+</style>
+</head>
+<body>
+<h1>ketall inventory</h1>
+<p>{{.Total}} resources, generated {{.Generated}}</p>
+<h2>Summary</h2>
+<div class="summaries">
+{{- range $summary := .Summaries}}
+<table>
+<tr><th>{{$summary.GroupBy}}</th><th>count</th></tr>
+{{- range $summary.Groups}}
+<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</div>
+<h2>Resources</h2>
+<p><input id="filter" type="search" placeholder="Filter" oninput="filterRows(this.value)"></p>
+<table id="objects">
+<thead>
+<tr>{{range $i, $h := .Header}}<th onclick="sortRows({{$i}})">{{$h}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- range $row := .Rows}}
+<tr>
+{{- range $i, $c := $row.Columns}}
+{{- if eq $i 0}}<td><details><summary>{{$c}}</summary><pre>{{$row.YAML}}</pre></details></td>
+{{- else if eq $i 2}}<td data-sort="{{$row.Created}}">{{$c}}</td>
+{{- else}}<td>{{$c}}</td>
+{{- end}}
+{{- end -}}
+</tr>
+{{- end}}
+</tbody>
+</table>
+<script>
+function filterRows(text) {
+  text = text.toLowerCase();
+  document.querySelectorAll("#objects tbody tr").forEach(function (row) {
+    row.style.display = row.textContent.toLowerCase().includes(text) ? "" : "none";
+  });
+}
+function sortKey(cell) {
+  var summary = cell.querySelector("summary");
+  return cell.dataset.sort || (summary ? summary.textContent : cell.textContent);
+}
+var sortState = {};
+function sortRows(column) {
+  var body = document.querySelector("#objects tbody");
+  var rows = Array.from(body.rows);
+  var ascending = sortState[column] = !sortState[column];
+  rows.sort(function (a, b) {
+    return (ascending ? 1 : -1) * sortKey(a.cells[column]).localeCompare(sortKey(b.cells[column]), undefined, {numeric: true});
+  });
+  rows.forEach(function (row) { body.appendChild(row); });
+}
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestHTMLPrinter_PrintObj(t *testing.T) {
+	deploy := newUnstructured("apps/v1", "Deployment", "default", "web")
+	deploy.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "helm"}})
+	cm := newUnstructured("v1", "ConfigMap", "default", "<script>")
+	ns := newUnstructured("v1", "Namespace", "", "default")
+	objects := util.ToV1List([]runtime.Object{deploy, util.ToV1List([]runtime.Object{cm}), ns})
+
+	buffer := &bytes.Buffer{}
+	p := &HTMLPrinter{now: func() time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) }}
+	assert.NoError(t, p.PrintObj(objects, buffer))
+
+	out := buffer.String()
+	assert.Contains(t, out, "<p>3 resources, generated 2020-01-01T00:00:00Z</p>")
+	assert.Contains(t, out, "<tr><td>&lt;cluster&gt;</td><td>1</td></tr>")
+	assert.Contains(t, out, "<tr><td>default</td><td>2</td></tr>")
+	assert.Contains(t, out, "<tr><td>deployment.apps</td><td>1</td></tr>")
+	assert.Contains(t, out, `<th onclick="sortRows( 0 )">NAME</th>`)
+	assert.Contains(t, out, "<td><details><summary>deployment.apps/web</summary><pre>apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: default\n</pre></details></td>")
+	assert.Contains(t, out, "configmap/&lt;script&gt;")
+	assert.NotContains(t, out, "helm")
+	// the managed fields of the original object are untouched
+	assert.Len(t, deploy.GetManagedFields(), 1)
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestJSONLinesPrinter_PrintObj(t *testing.T) {
+	objects := util.ToV1List([]runtime.Object{
+		newUnstructured("v1", "ConfigMap", "default", "cfg"),
+		util.ToV1List([]runtime.Object{newUnstructured("apps/v1", "Deployment", "default", "web")}),
+	})
+
+	buffer := &bytes.Buffer{}
+	p := NewFlattenListAdapterPrinter(&JSONLinesPrinter{})
+	assert.NoError(t, p.PrintObj(objects, buffer))
+	assert.Equal(t, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cfg","namespace":"default"}}
+{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","namespace":"default"}}
+`, buffer.String())
+}
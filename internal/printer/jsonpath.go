@@ -0,0 +1,82 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+var jsonRegexp = regexp.MustCompile(`^\{\.?([^{}]+)\}$|^\.?([^{}]+)$`)
+
+// relaxedJSONPathExpression accepts JSONPath expressions with or without the enclosing braces
+// and leading dot, such as 'metadata.name', '.metadata.name' or '{.metadata.name}'.
+func relaxedJSONPathExpression(pathExpression string) (string, error) {
+	if len(pathExpression) == 0 {
+		return pathExpression, nil
+	}
+	submatches := jsonRegexp.FindStringSubmatch(pathExpression)
+	if submatches == nil {
+		return "", errors.Errorf("unexpected path string, expected a 'name1.name2' or '.name1.name2' or '{name1.name2}' or '{.name1.name2}'")
+	}
+	if len(submatches) != 3 {
+		return "", errors.Errorf("unexpected submatch list: %v", submatches)
+	}
+	var fieldSpec string
+	if len(submatches[1]) != 0 {
+		fieldSpec = submatches[1]
+	} else {
+		fieldSpec = submatches[2]
+	}
+	return "{." + strings.TrimPrefix(fieldSpec, ".") + "}", nil
+}
+
+// newJSONPath parses a relaxed JSONPath expression which tolerates missing keys.
+func newJSONPath(name, pathExpression string) (*jsonpath.JSONPath, error) {
+	expr, err := relaxedJSONPathExpression(pathExpression)
+	if err != nil {
+		return nil, err
+	}
+	parser := jsonpath.New(name).AllowMissingKeys(true)
+	if err := parser.Parse(expr); err != nil {
+		return nil, errors.Wrapf(err, "parse jsonpath %s", pathExpression)
+	}
+	return parser, nil
+}
+
+// objectContent returns the object as generic map, so that JSONPath expressions refer to the
+// serialized field names.
+func objectContent(o runtime.Object) (map[string]interface{}, error) {
+	if u, ok := o.(runtime.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	content := map[string]interface{}{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
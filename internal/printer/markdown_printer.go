@@ -0,0 +1,126 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "<", "&lt;", ">", "&gt;", "\n", " ")
+
+// MarkdownPrinter renders the table as GitHub-flavored Markdown, optionally with one section
+// per namespace or kind.
+type MarkdownPrinter struct {
+	// Table selects the columns, including the wide and label columns
+	Table TablePrinter
+	// SectionBy is empty, or one of namespace or kind
+	SectionBy string
+}
+
+// ValidateMarkdownSectionBy checks that the given value is a supported section key.
+func ValidateMarkdownSectionBy(sectionBy string) error {
+	switch sectionBy {
+	case "", SummaryByNamespace, SummaryByKind:
+		return nil
+	}
+	return errors.Errorf("%s is not a valid markdown section (must be one of '%s' or '%s')", sectionBy, SummaryByNamespace, SummaryByKind)
+}
+
+func (p *MarkdownPrinter) PrintObj(r runtime.Object, w io.Writer) error {
+	if err := ValidateMarkdownSectionBy(p.SectionBy); err != nil {
+		return err
+	}
+
+	items, err := flatten(r)
+	if err != nil {
+		return err
+	}
+
+	if p.SectionBy == "" {
+		return p.printTable(w, items)
+	}
+
+	// sections appear in the order of their first object
+	var sections []string
+	sectionItems := map[string][]runtime.Object{}
+	for _, o := range items {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+		key := groupKey(p.SectionBy, o, acc)
+		if _, ok := sectionItems[key]; !ok {
+			sections = append(sections, key)
+		}
+		sectionItems[key] = append(sectionItems[key], o)
+	}
+
+	for i, section := range sections {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "## %s\n\n", markdownEscaper.Replace(section)); err != nil {
+			return err
+		}
+		if err := p.printTable(w, sectionItems[section]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *MarkdownPrinter) printTable(w io.Writer, items []runtime.Object) error {
+	header := p.Table.header()
+	separators := make([]string, len(header))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	if err := printMarkdownRow(w, header); err != nil {
+		return err
+	}
+	if err := printMarkdownRow(w, separators); err != nil {
+		return err
+	}
+
+	for _, o := range items {
+		row, err := p.Table.row(o)
+		if err != nil {
+			return err
+		}
+		if err := printMarkdownRow(w, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printMarkdownRow(w io.Writer, columns []string) error {
+	escaped := make([]string, 0, len(columns))
+	for _, c := range columns {
+		escaped = append(escaped, markdownEscaper.Replace(c))
+	}
+	_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+	return err
+}
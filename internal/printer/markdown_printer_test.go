@@ -0,0 +1,81 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestMarkdownPrinter_PrintObj(t *testing.T) {
+	deploy := newUnstructured("apps/v1", "Deployment", "default", "web")
+	deploy.SetLabels(map[string]string{"note": "a|b"})
+	cm := newUnstructured("v1", "ConfigMap", "kube-system", "cfg")
+	ns := newUnstructured("v1", "Namespace", "", "default")
+	objects := util.ToV1List([]runtime.Object{deploy, util.ToV1List([]runtime.Object{cm}), ns})
+
+	tests := []struct {
+		name    string
+		printer MarkdownPrinter
+		want    string
+	}{
+		{
+			name:    "single table",
+			printer: MarkdownPrinter{Table: TablePrinter{LabelColumns: []string{"note"}}},
+			want: `| NAME | NAMESPACE | AGE | NOTE |
+| --- | --- | --- | --- |
+| deployment.apps/web | default | &lt;unknown&gt; | a\|b |
+| configmap/cfg | kube-system | &lt;unknown&gt; |  |
+| namespace/default |  | &lt;unknown&gt; |  |
+`,
+		},
+		{
+			name:    "sections by namespace",
+			printer: MarkdownPrinter{SectionBy: SummaryByNamespace},
+			want: `## default
+
+| NAME | NAMESPACE | AGE |
+| --- | --- | --- |
+| deployment.apps/web | default | &lt;unknown&gt; |
+
+## kube-system
+
+| NAME | NAMESPACE | AGE |
+| --- | --- | --- |
+| configmap/cfg | kube-system | &lt;unknown&gt; |
+
+## &lt;cluster&gt;
+
+| NAME | NAMESPACE | AGE |
+| --- | --- | --- |
+| namespace/default |  | &lt;unknown&gt; |
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			assert.NoError(t, test.printer.PrintObj(objects, buffer))
+			assert.Equal(t, test.want, buffer.String())
+		})
+	}
+}
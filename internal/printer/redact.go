@@ -0,0 +1,251 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/klog/v2"
+)
+
+// RedactedPrefix starts every redacted value, it is followed by a truncated SHA-256 hash of the
+// original value, so that changes of redacted values can still be detected.
+const RedactedPrefix = "redacted:sha256:"
+
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// secretPaths are redacted in every Secret
+var secretPaths = []string{".data", ".stringData"}
+
+// RedactAdapter replaces the data of Secrets and the values at the given JSONPaths of all
+// objects with a hash. Objects without anything to redact are passed on unchanged.
+type RedactAdapter struct {
+	printers.ResourcePrinter
+	secretPaths []redactPath
+	paths       []redactPath
+}
+
+// redactPath holds the nodes of a parsed JSONPath expression.
+type redactPath []jsonpath.Node
+
+func NewRedactAdapterPrinter(printer printers.ResourcePrinter, paths []string) (printers.ResourcePrinter, error) {
+	klog.V(2).Infof("Wrapping %T with RedactAdapter", printer)
+	secret, err := parseRedactPaths(secretPaths)
+	if err != nil {
+		return nil, err
+	}
+	custom, err := parseRedactPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	return &RedactAdapter{ResourcePrinter: printer, secretPaths: secret, paths: custom}, nil
+}
+
+func (n *RedactAdapter) PrintObj(r runtime.Object, w io.Writer) error {
+	items, err := flatten(r)
+	if err != nil {
+		return err
+	}
+
+	redacted := make([]runtime.Object, 0, len(items))
+	for _, o := range items {
+		isSecret := getObjectGroupKind(o).String() == "Secret"
+		if !isSecret && len(n.paths) == 0 {
+			redacted = append(redacted, o)
+			continue
+		}
+
+		u, err := toUnstructured(o)
+		if err != nil {
+			return err
+		}
+		if isSecret {
+			for _, p := range n.secretPaths {
+				p.redact(u.Object)
+			}
+			// the last applied configuration contains the data in plain text
+			if _, found := u.GetAnnotations()[lastAppliedAnnotation]; found {
+				_ = unstructured.SetNestedField(u.Object, redactValue(u.GetAnnotations()[lastAppliedAnnotation]), "metadata", "annotations", lastAppliedAnnotation)
+			}
+		}
+		for _, p := range n.paths {
+			p.redact(u.Object)
+		}
+		redacted = append(redacted, u)
+	}
+
+	return n.ResourcePrinter.PrintObj(util.ToV1List(redacted), w)
+}
+
+func parseRedactPaths(expressions []string) ([]redactPath, error) {
+	var paths []redactPath
+	for _, expression := range expressions {
+		p, err := parseRedactPath(expression)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse redaction path %s", expression)
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// parseRedactPath accepts a relaxed JSONPath expression which consists of fields, wildcards
+// and array indexes, such as '.spec.credentials[*].password'.
+func parseRedactPath(expression string) (redactPath, error) {
+	expr, err := relaxedJSONPathExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser, err := jsonpath.Parse("redact", expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(parser.Root.Nodes) != 1 {
+		return nil, fmt.Errorf("must be a single expression")
+	}
+	action, ok := parser.Root.Nodes[0].(*jsonpath.ListNode)
+	if !ok {
+		return nil, fmt.Errorf("must be a single expression")
+	}
+	for _, node := range action.Nodes {
+		switch node := node.(type) {
+		case *jsonpath.FieldNode, *jsonpath.WildcardNode:
+		case *jsonpath.ArrayNode:
+			if !allElements(node) && index(node) < 0 {
+				return nil, fmt.Errorf("only [*] and [<index>] are supported, got %s", node)
+			}
+		default:
+			return nil, fmt.Errorf("only fields, wildcards and array indexes are supported, got %s", node)
+		}
+	}
+	return action.Nodes, nil
+}
+
+func (p redactPath) redact(content map[string]interface{}) {
+	redactAt(content, p)
+}
+
+// redactAt redacts the values below the given nodes in place and returns the possibly replaced value.
+func redactAt(value interface{}, nodes []jsonpath.Node) interface{} {
+	if len(nodes) == 0 {
+		return redactValue(value)
+	}
+
+	switch node := nodes[0].(type) {
+	case *jsonpath.FieldNode:
+		if m, ok := value.(map[string]interface{}); ok {
+			if v, found := m[node.Value]; found {
+				m[node.Value] = redactAt(v, nodes[1:])
+			}
+		}
+	case *jsonpath.WildcardNode:
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key := range v {
+				v[key] = redactAt(v[key], nodes[1:])
+			}
+		case []interface{}:
+			for i := range v {
+				v[i] = redactAt(v[i], nodes[1:])
+			}
+		}
+	case *jsonpath.ArrayNode:
+		if s, ok := value.([]interface{}); ok {
+			for i := range s {
+				if allElements(node) || i == index(node) {
+					s[i] = redactAt(s[i], nodes[1:])
+				}
+			}
+		}
+	}
+	return value
+}
+
+// redactValue replaces all scalar values with their hash.
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = redactValue(v[key])
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+		return v
+	case string:
+		if strings.HasPrefix(v, RedactedPrefix) {
+			return v
+		}
+		return hash(v)
+	default:
+		return hash(fmt.Sprint(v))
+	}
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return RedactedPrefix + hex.EncodeToString(sum[:])[:16]
+}
+
+func allElements(node *jsonpath.ArrayNode) bool {
+	return !node.Params[0].Known && !node.Params[1].Known && !node.Params[2].Known
+}
+
+// index returns the index of an array node which selects a single element, or -1.
+func index(node *jsonpath.ArrayNode) int {
+	start, end := node.Params[0], node.Params[1]
+	if start.Known && start.Value >= 0 && end.Value == start.Value+1 && !node.Params[2].Known {
+		return start.Value
+	}
+	return -1
+}
+
+// IsRedacted tells if the object contains any redacted value.
+func IsRedacted(content map[string]interface{}) bool {
+	var found bool
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case string:
+			found = found || strings.HasPrefix(v, RedactedPrefix)
+		}
+	}
+	walk(content)
+	return found
+}
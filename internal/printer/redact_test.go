@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRedactAdapter_PrintObj(t *testing.T) {
+	secret := newUnstructured("v1", "Secret", "default", "token")
+	_ = unstructured.SetNestedStringMap(secret.Object, map[string]string{"token": "c2VjcmV0"}, "data")
+	_ = unstructured.SetNestedStringMap(secret.Object, map[string]string{"password": "secret"}, "stringData")
+	secret.SetAnnotations(map[string]string{lastAppliedAnnotation: `{"data":{"token":"c2VjcmV0"}}`, "owner": "team-a"})
+
+	widget := newUnstructured("example.com/v1", "Widget", "default", "w")
+	_ = unstructured.SetNestedField(widget.Object, "secret", "spec", "token")
+	_ = unstructured.SetNestedSlice(widget.Object, []interface{}{
+		map[string]interface{}{"user": "a", "password": "secret"},
+		map[string]interface{}{"user": "b", "password": int64(1234)},
+	}, "spec", "credentials")
+
+	cfg := newUnstructured("v1", "ConfigMap", "default", "cfg")
+	_ = unstructured.SetNestedStringMap(cfg.Object, map[string]string{"key": "value"}, "data")
+
+	objects := util.ToV1List([]runtime.Object{secret, util.ToV1List([]runtime.Object{widget, cfg})})
+
+	p, err := NewRedactAdapterPrinter(NewFlattenListAdapterPrinter(&JSONLinesPrinter{}), []string{"{.spec.token}", "spec.credentials[*].password", ".spec.missing[0]"})
+	assert.NoError(t, err)
+	buffer := &bytes.Buffer{}
+	assert.NoError(t, p.PrintObj(objects, buffer))
+	assert.Equal(t, `{"apiVersion":"v1","data":{"token":"`+hash("c2VjcmV0")+`"},"kind":"Secret","metadata":{"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"`+hash(`{"data":{"token":"c2VjcmV0"}}`)+`","owner":"team-a"},"name":"token","namespace":"default"},"stringData":{"password":"redacted:sha256:2bb80d537b1da3e3"}}
+{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"w","namespace":"default"},"spec":{"credentials":[{"password":"redacted:sha256:2bb80d537b1da3e3","user":"a"},{"password":"redacted:sha256:03ac674216f3e15c","user":"b"}],"token":"redacted:sha256:2bb80d537b1da3e3"}}
+{"apiVersion":"v1","data":{"key":"value"},"kind":"ConfigMap","metadata":{"name":"cfg","namespace":"default"}}
+`, buffer.String())
+
+	// the original objects are not modified
+	token, _, _ := unstructured.NestedString(secret.Object, "data", "token")
+	assert.Equal(t, "c2VjcmV0", token)
+	assert.False(t, IsRedacted(widget.Object))
+}
+
+func TestParseRedactPath(t *testing.T) {
+	content := map[string]interface{}{
+		"items": []interface{}{"a", "b", "c"},
+		"nested": map[string]interface{}{
+			"x": map[string]interface{}{"key": "value"},
+			"y": map[string]interface{}{"key": true},
+		},
+	}
+
+	p, err := parseRedactPath(".items[1]")
+	assert.NoError(t, err)
+	p.redact(content)
+	assert.Equal(t, []interface{}{"a", hash("b"), "c"}, content["items"])
+
+	p, err = parseRedactPath(".nested.*.key")
+	assert.NoError(t, err)
+	p.redact(content)
+	assert.True(t, IsRedacted(content["nested"].(map[string]interface{})["x"].(map[string]interface{})))
+	assert.Equal(t, hash("true"), content["nested"].(map[string]interface{})["y"].(map[string]interface{})["key"])
+
+	for _, invalid := range []string{"{.a}{.b}", ".items[0:2]", ".items[?(@.a)]", "{"} {
+		_, err = parseRedactPath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
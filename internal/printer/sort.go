@@ -0,0 +1,214 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/klog/v2"
+)
+
+const (
+	SortByName      = "name"
+	SortByNamespace = "namespace"
+	SortByKind      = "kind"
+	SortByAge       = "age"
+)
+
+// compareFunc returns a negative number if a sorts before b, a positive number if b sorts
+// before a, and zero if both are equal.
+type compareFunc func(a, b runtime.Object) int
+
+// Sorter orders the fetched objects, so that the output does not depend on the order in which
+// the objects were fetched. It is safe for concurrent use.
+type Sorter struct {
+	compare compareFunc
+	// jsonPath is parsed anew for each Sort, because a JSONPath parser keeps state while it
+	// evaluates and must not be shared between goroutines
+	jsonPath string
+}
+
+// NewSorter creates a Sorter for the given sort key, which is one of name, namespace, kind,
+// age (youngest first), or a JSONPath expression such as '.metadata.uid'. Objects with equal
+// sort keys, or all objects if the sort key is empty, are ordered by API group, kind,
+// namespace and name.
+func NewSorter(sortBy string) (*Sorter, error) {
+	switch sortBy {
+	case "":
+		return &Sorter{}, nil
+	case SortByName:
+		return &Sorter{compare: byName}, nil
+	case SortByNamespace:
+		return &Sorter{compare: byNamespace}, nil
+	case SortByKind:
+		return &Sorter{compare: byKind}, nil
+	case SortByAge:
+		return &Sorter{compare: byAge}, nil
+	}
+
+	if _, err := newJSONPath("sort-by", sortBy); err != nil {
+		return nil, errors.Wrapf(err, "invalid sort key %s (must be one of %s, %s, %s, %s, or a JSONPath expression)",
+			sortBy, SortByName, SortByNamespace, SortByKind, SortByAge)
+	}
+	return &Sorter{jsonPath: sortBy}, nil
+}
+
+// Sort returns a flat list of all objects in sorted order.
+func (s *Sorter) Sort(r runtime.Object) (runtime.Object, error) {
+	items, err := flatten(r)
+	if err != nil {
+		return nil, err
+	}
+
+	compare := s.compare
+	if s.jsonPath != "" {
+		parser, err := newJSONPath("sort-by", s.jsonPath)
+		if err != nil {
+			return nil, err
+		}
+		compare = byJSONPath(parser)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if compare != nil {
+			if c := compare(items[i], items[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return byCanonicalOrder(items[i], items[j]) < 0
+	})
+	return util.ToV1List(items), nil
+}
+
+func byCanonicalOrder(a, b runtime.Object) int {
+	ga, gb := getObjectGroupKind(a), getObjectGroupKind(b)
+	if c := strings.Compare(ga.Group, gb.Group); c != 0 {
+		return c
+	}
+	if c := strings.Compare(ga.Kind, gb.Kind); c != 0 {
+		return c
+	}
+	if c := byNamespace(a, b); c != 0 {
+		return c
+	}
+	return byName(a, b)
+}
+
+func byName(a, b runtime.Object) int {
+	return strings.Compare(accessor(a).GetName(), accessor(b).GetName())
+}
+
+func byNamespace(a, b runtime.Object) int {
+	return strings.Compare(accessor(a).GetNamespace(), accessor(b).GetNamespace())
+}
+
+func byKind(a, b runtime.Object) int {
+	ga, gb := getObjectGroupKind(a), getObjectGroupKind(b)
+	if c := strings.Compare(ga.Kind, gb.Kind); c != 0 {
+		return c
+	}
+	return strings.Compare(ga.Group, gb.Group)
+}
+
+func byAge(a, b runtime.Object) int {
+	ta, tb := accessor(a).GetCreationTimestamp(), accessor(b).GetCreationTimestamp()
+	switch {
+	case ta.Equal(&tb):
+		return 0
+	case tb.Before(&ta):
+		return -1
+	default:
+		return 1
+	}
+}
+
+func byJSONPath(parser *jsonpath.JSONPath) compareFunc {
+	return func(a, b runtime.Object) int {
+		va, okA := jsonPathValue(parser, a)
+		vb, okB := jsonPathValue(parser, b)
+		switch {
+		case !okA && !okB:
+			return 0
+		case !okA:
+			return 1 // missing values go last
+		case !okB:
+			return -1
+		}
+
+		fa, numA := toFloat(va)
+		fb, numB := toFloat(vb)
+		if numA && numB {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+		return strings.Compare(fmt.Sprint(va), fmt.Sprint(vb))
+	}
+}
+
+func jsonPathValue(parser *jsonpath.JSONPath, o runtime.Object) (interface{}, bool) {
+	content, err := objectContent(o)
+	if err != nil {
+		klog.Warningf("could not convert object for sorting: %s", err)
+		return nil, false
+	}
+	results, err := parser.FindResults(content)
+	if err != nil {
+		return nil, false
+	}
+	for _, r := range results {
+		if len(r) > 0 && r[0].IsValid() && r[0].CanInterface() {
+			return r[0].Interface(), true
+		}
+	}
+	return nil, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// accessor returns the object metadata, or empty metadata if the object has none.
+func accessor(o runtime.Object) metav1.Object {
+	acc, err := meta.Accessor(o)
+	if err != nil {
+		return &metav1.ObjectMeta{}
+	}
+	return acc
+}
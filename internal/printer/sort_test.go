@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSorter_Sort(t *testing.T) {
+	now := time.Now()
+	newObj := func(apiVersion, kind, namespace, name string, age time.Duration, replicas int64) runtime.Object {
+		o := newUnstructured(apiVersion, kind, namespace, name)
+		o.SetCreationTimestamp(metav1.Time{Time: now.Add(-age)})
+		if replicas > 0 {
+			o.Object["spec"] = map[string]interface{}{"replicas": replicas}
+		}
+		return o
+	}
+
+	// nested lists, as returned by the incremental fetch
+	objects := util.ToV1List([]runtime.Object{
+		util.ToV1List([]runtime.Object{
+			newObj("v1", "Pod", "b", "web", 3*time.Hour, 0),
+			newObj("v1", "ConfigMap", "a", "cfg", time.Hour, 0),
+		}),
+		util.ToV1List([]runtime.Object{
+			newObj("apps/v1", "Deployment", "b", "api", 2*time.Hour, 10),
+			newObj("apps/v1", "Deployment", "a", "web", 4*time.Hour, 2),
+		}),
+		newObj("v1", "Pod", "a", "api", time.Minute, 0),
+	})
+
+	tests := []struct {
+		sortBy    string
+		want      []string
+		shouldErr bool
+	}{
+		{
+			sortBy: "",
+			want:   []string{"ConfigMap/a/cfg", "Pod/a/api", "Pod/b/web", "Deployment/a/web", "Deployment/b/api"},
+		},
+		{
+			sortBy: "name",
+			want:   []string{"Pod/a/api", "Deployment/b/api", "ConfigMap/a/cfg", "Pod/b/web", "Deployment/a/web"},
+		},
+		{
+			sortBy: "namespace",
+			want:   []string{"ConfigMap/a/cfg", "Pod/a/api", "Deployment/a/web", "Pod/b/web", "Deployment/b/api"},
+		},
+		{
+			sortBy: "kind",
+			want:   []string{"ConfigMap/a/cfg", "Deployment/a/web", "Deployment/b/api", "Pod/a/api", "Pod/b/web"},
+		},
+		{
+			sortBy: "age",
+			want:   []string{"Pod/a/api", "ConfigMap/a/cfg", "Deployment/b/api", "Pod/b/web", "Deployment/a/web"},
+		},
+		{
+			sortBy: ".spec.replicas",
+			want:   []string{"Deployment/a/web", "Deployment/b/api", "ConfigMap/a/cfg", "Pod/a/api", "Pod/b/web"},
+		},
+		{
+			sortBy: "{.metadata.name}",
+			want:   []string{"Pod/a/api", "Deployment/b/api", "ConfigMap/a/cfg", "Pod/b/web", "Deployment/a/web"},
+		},
+		{
+			sortBy:    "{.metadata.name",
+			shouldErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.sortBy, func(t *testing.T) {
+			sorter, err := NewSorter(test.sortBy)
+			if test.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			sorted, err := sorter.Sort(objects)
+			assert.NoError(t, err)
+
+			items, err := meta.ExtractList(sorted)
+			assert.NoError(t, err)
+			var actual []string
+			for _, o := range items {
+				acc, _ := meta.Accessor(o)
+				actual = append(actual, o.GetObjectKind().GroupVersionKind().Kind+"/"+acc.GetNamespace()+"/"+acc.GetName())
+			}
+			assert.Equal(t, test.want, actual)
+		})
+	}
+}
+
+func TestSorter_SortConcurrently(t *testing.T) {
+	var objects []runtime.Object
+	for i := 0; i < 50; i++ {
+		o := newUnstructured("v1", "ConfigMap", "default", fmt.Sprintf("cfg-%02d", i))
+		o.SetUID(types.UID(fmt.Sprintf("%02d", 49-i)))
+		objects = append(objects, o)
+	}
+	sorter, err := NewSorter(".metadata.uid")
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sorted, err := sorter.Sort(util.ToV1List(objects))
+			assert.NoError(t, err)
+			items, _ := meta.ExtractList(sorted)
+			assert.Equal(t, "cfg-49", items[0].(metav1.Object).GetName())
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	SummaryByKind      = "kind"
+	SummaryByNamespace = "namespace"
+	SummaryByGroup     = "group"
+)
+
+// SummaryPrinter counts the objects by kind, namespace or API group instead of listing them.
+type SummaryPrinter struct {
+	// GroupBy is one of kind, namespace or group
+	GroupBy string
+	// ShowAges adds the ages of the oldest and newest object of each group
+	ShowAges bool
+	// JSON prints the summary as JSON document instead of a table
+	JSON bool
+}
+
+// Summary is the aggregated view of a list of objects.
+type Summary struct {
+	GroupBy string         `json:"groupBy"`
+	Total   int            `json:"total"`
+	Groups  []SummaryGroup `json:"groups"`
+}
+
+// SummaryGroup holds the number of objects with the same grouping key.
+type SummaryGroup struct {
+	Name   string       `json:"name"`
+	Count  int          `json:"count"`
+	Oldest *metav1.Time `json:"oldest,omitempty"`
+	Newest *metav1.Time `json:"newest,omitempty"`
+}
+
+// ValidateSummaryGroupBy checks that the given value is a supported grouping key.
+func ValidateSummaryGroupBy(groupBy string) error {
+	switch groupBy {
+	case SummaryByKind, SummaryByNamespace, SummaryByGroup:
+		return nil
+	}
+	return errors.Errorf("%s is not a valid summary (must be one of '%s', '%s' or '%s')", groupBy, SummaryByKind, SummaryByNamespace, SummaryByGroup)
+}
+
+func (p *SummaryPrinter) PrintObj(r runtime.Object, w io.Writer) error {
+	summary, err := p.summarize(r)
+	if err != nil {
+		return err
+	}
+
+	if p.JSON {
+		data, err := json.MarshalIndent(summary, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 4, 4, 2, ' ', 0)
+	columns := []string{strings.ToUpper(summary.GroupBy), "COUNT"}
+	if p.ShowAges {
+		columns = append(columns, "OLDEST", "NEWEST")
+	}
+	if _, err := fmt.Fprintf(tw, "%s\n", strings.Join(columns, "\t")); err != nil {
+		return err
+	}
+	for _, g := range summary.Groups {
+		columns := []string{g.Name, fmt.Sprint(g.Count)}
+		if p.ShowAges {
+			columns = append(columns, translateTimestampSince(*g.Oldest), translateTimestampSince(*g.Newest))
+		}
+		if _, err := fmt.Fprintf(tw, "%s\n", strings.Join(columns, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+func (p *SummaryPrinter) summarize(r runtime.Object) (*Summary, error) {
+	if err := ValidateSummaryGroupBy(p.GroupBy); err != nil {
+		return nil, err
+	}
+
+	items, err := flatten(r)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]*SummaryGroup{}
+	for _, o := range items {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			return nil, err
+		}
+
+		key := groupKey(p.GroupBy, o, acc)
+		g, ok := groups[key]
+		if !ok {
+			g = &SummaryGroup{Name: key}
+			groups[key] = g
+		}
+		g.Count++
+
+		if !p.ShowAges {
+			continue
+		}
+		created := acc.GetCreationTimestamp()
+		if g.Oldest == nil || created.Before(g.Oldest) {
+			g.Oldest = created.DeepCopy()
+		}
+		if g.Newest == nil || g.Newest.Before(&created) {
+			g.Newest = created.DeepCopy()
+		}
+	}
+
+	summary := &Summary{GroupBy: p.GroupBy, Total: len(items), Groups: []SummaryGroup{}}
+	for _, g := range groups {
+		summary.Groups = append(summary.Groups, *g)
+	}
+	sort.Slice(summary.Groups, func(i, j int) bool {
+		return summary.Groups[i].Name < summary.Groups[j].Name
+	})
+	return summary, nil
+}
+
+// groupKey returns the name of the group of the object, where groupBy is one of kind, namespace or group.
+func groupKey(groupBy string, o runtime.Object, acc metav1.Object) string {
+	switch groupBy {
+	case SummaryByNamespace:
+		if acc.GetNamespace() == "" {
+			return "<cluster>"
+		}
+		return acc.GetNamespace()
+	case SummaryByGroup:
+		if group := getObjectGroupKind(o).Group; group != "" {
+			return group
+		}
+		return "core"
+	default:
+		groupKind := getObjectGroupKind(o)
+		if groupKind.Group == "" {
+			return strings.ToLower(groupKind.Kind)
+		}
+		return fmt.Sprintf("%s.%s", strings.ToLower(groupKind.Kind), groupKind.Group)
+	}
+}
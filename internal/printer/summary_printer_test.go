@@ -0,0 +1,108 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSummaryPrinter_PrintObj(t *testing.T) {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newObj := func(apiVersion, kind, namespace, name string, age time.Duration) runtime.Object {
+		o := newUnstructured(apiVersion, kind, namespace, name)
+		o.SetCreationTimestamp(metav1.Time{Time: created.Add(-age)})
+		return o
+	}
+	objects := util.ToV1List([]runtime.Object{
+		newObj("v1", "Pod", "a", "p1", time.Hour),
+		util.ToV1List([]runtime.Object{
+			newObj("v1", "Pod", "b", "p2", 2*time.Hour),
+			newObj("apps/v1", "Deployment", "a", "d", 3*time.Hour),
+		}),
+		newObj("v1", "Namespace", "", "a", 4*time.Hour),
+	})
+
+	tests := []struct {
+		name    string
+		printer SummaryPrinter
+		want    string
+	}{
+		{
+			name:    "by kind",
+			printer: SummaryPrinter{GroupBy: SummaryByKind},
+			want: `KIND             COUNT
+deployment.apps  1
+namespace        1
+pod              2
+`,
+		},
+		{
+			name:    "by namespace",
+			printer: SummaryPrinter{GroupBy: SummaryByNamespace},
+			want: `NAMESPACE  COUNT
+<cluster>  1
+a          2
+b          1
+`,
+		},
+		{
+			name:    "by group as json",
+			printer: SummaryPrinter{GroupBy: SummaryByGroup, ShowAges: true, JSON: true},
+			want: `{
+    "groupBy": "group",
+    "total": 4,
+    "groups": [
+        {
+            "name": "apps",
+            "count": 1,
+            "oldest": "2019-12-31T21:00:00Z",
+            "newest": "2019-12-31T21:00:00Z"
+        },
+        {
+            "name": "core",
+            "count": 3,
+            "oldest": "2019-12-31T20:00:00Z",
+            "newest": "2019-12-31T23:00:00Z"
+        }
+    ]
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			err := test.printer.PrintObj(objects, buffer)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, buffer.String())
+		})
+	}
+}
+
+func TestSummaryPrinter_InvalidGroupBy(t *testing.T) {
+	p := &SummaryPrinter{GroupBy: "unknown"}
+	err := p.PrintObj(util.ToV1List(nil), &bytes.Buffer{})
+	assert.Error(t, err)
+}
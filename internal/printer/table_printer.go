@@ -23,22 +23,57 @@ import (
 	"strings"
 	"time"
 
+	"github.com/corneliusweig/ketall/internal/util"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/cli-runtime/pkg/printers"
 )
 
-type TablePrinter struct{}
-
 const (
-	tableRow = "%s\t%s\t%s\t\n"
+	// TimestampsRelative shows the age of objects
+	TimestampsRelative = "relative"
+	// TimestampsAbsolute shows the creation time of objects
+	TimestampsAbsolute = "absolute"
+	// TimestampsBoth shows the age and the creation time of objects
+	TimestampsBoth = "both"
 )
 
-func (*TablePrinter) PrintObj(r runtime.Object, w io.Writer) error {
+// ValidateTimestamps checks that the timestamp mode is known, an empty mode is relative.
+func ValidateTimestamps(timestamps string) error {
+	switch timestamps {
+	case "", TimestampsRelative, TimestampsAbsolute, TimestampsBoth:
+		return nil
+	}
+	return fmt.Errorf("%s is not a valid timestamp mode (must be one of '%s', '%s' or '%s')", timestamps, TimestampsRelative, TimestampsAbsolute, TimestampsBoth)
+}
+
+type TablePrinter struct {
+	// ShowManagers adds a MANAGER column with the field managers of each object
+	ShowManagers bool
+	// Wide adds the API version, controlling owner, UID and resource version of each object
+	Wide bool
+	// LabelColumns adds a column with the value of each of the given labels
+	LabelColumns []string
+	// ShowLabels adds a LABELS column with all labels of each object
+	ShowLabels bool
+	// NoHeaders omits the header line
+	NoHeaders bool
+	// Color adds escape codes which color the columns
+	Color bool
+	// Timestamps shows the age, the creation time, or both
+	Timestamps string
+	// LocalTime renders absolute timestamps in the local time zone instead of UTC
+	LocalTime bool
+	// ShowLastUpdated adds a LAST-UPDATED column with the latest time of the managed fields
+	ShowLastUpdated bool
+}
+
+func (p *TablePrinter) PrintObj(r runtime.Object, w io.Writer) error {
 	if printers.InternalObjectPreventer.IsForbidden(reflect.Indirect(reflect.ValueOf(r)).Type().PkgPath()) {
 		return fmt.Errorf(printers.InternalObjectPrinterErr)
 	}
@@ -47,32 +82,109 @@ func (*TablePrinter) PrintObj(r runtime.Object, w io.Writer) error {
 		return fmt.Errorf("missing apiVersion or kind; try GetObjectKind().SetGroupVersionKind() if you know the type")
 	}
 
-	if err := printObj(r, w); err != nil {
+	if err := p.printObj(r, w); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (*TablePrinter) PrintHeader(w io.Writer) error {
-	_, err := fmt.Fprintf(w, "%s\t%s\t%s\n", "NAME", "NAMESPACE", "AGE")
+func (p *TablePrinter) PrintHeader(w io.Writer) error {
+	if p.NoHeaders {
+		return nil
+	}
+	header := p.header()
+	if p.Color {
+		header = colorize(colorBold, header)
+	}
+	_, err := fmt.Fprintf(w, "%s\n", strings.Join(header, "\t"))
 	return err
 }
 
-func printObj(o runtime.Object, w io.Writer) error {
+func (p *TablePrinter) printObj(o runtime.Object, w io.Writer) error {
+	columns, err := p.row(o)
+	if err != nil {
+		return err
+	}
+	if p.Color {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+		columns = colorizeRow(getObjectGroupKind(o), acc, columns, time.Now())
+	}
+	if _, err := fmt.Fprintf(w, "%s\t\n", strings.Join(columns, "\t")); err != nil {
+		return err
+	}
+	return nil
+}
+
+// header returns the column titles of the table.
+func (p *TablePrinter) header() []string {
+	columns := []string{"NAME", "NAMESPACE"}
+	switch p.Timestamps {
+	case TimestampsAbsolute:
+		columns = append(columns, "CREATED")
+	case TimestampsBoth:
+		columns = append(columns, "AGE", "CREATED")
+	default:
+		columns = append(columns, "AGE")
+	}
+	if p.ShowLastUpdated {
+		columns = append(columns, "LAST-UPDATED")
+	}
+	if p.ShowManagers {
+		columns = append(columns, "MANAGER")
+	}
+	if p.Wide {
+		columns = append(columns, "APIVERSION", "OWNER", "UID", "RESOURCEVERSION")
+	}
+	for _, label := range p.LabelColumns {
+		// like kubectl, the header omits the prefix of the label key
+		columns = append(columns, strings.ToUpper(label[strings.LastIndex(label, "/")+1:]))
+	}
+	if p.ShowLabels {
+		columns = append(columns, "LABELS")
+	}
+	return columns
+}
+
+// row returns the column values of the table for the given object.
+func (p *TablePrinter) row(o runtime.Object) ([]string, error) {
 	groupKind := getObjectGroupKind(o)
 
 	acc, err := meta.Accessor(o)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	name := fullName(acc.GetName(), groupKind)
 	timestamp := acc.GetCreationTimestamp()
 	namespace := acc.GetNamespace()
-	if _, err := fmt.Fprintf(w, tableRow, name, namespace, translateTimestampSince(timestamp)); err != nil {
-		return err
+	columns := []string{name, namespace}
+	switch p.Timestamps {
+	case TimestampsAbsolute:
+		columns = append(columns, p.absoluteTimestamp(timestamp))
+	case TimestampsBoth:
+		columns = append(columns, translateTimestampSince(timestamp), p.absoluteTimestamp(timestamp))
+	default:
+		columns = append(columns, translateTimestampSince(timestamp))
 	}
-	return nil
+	if p.ShowLastUpdated {
+		columns = append(columns, p.lastUpdated(acc))
+	}
+	if p.ShowManagers {
+		columns = append(columns, managers(acc))
+	}
+	if p.Wide {
+		columns = append(columns, o.GetObjectKind().GroupVersionKind().GroupVersion().String(), owner(acc), string(acc.GetUID()), acc.GetResourceVersion())
+	}
+	for _, label := range p.LabelColumns {
+		columns = append(columns, acc.GetLabels()[label])
+	}
+	if p.ShowLabels {
+		columns = append(columns, labels.FormatLabels(acc.GetLabels()))
+	}
+	return columns, nil
 }
 
 func getObjectGroupKind(obj runtime.Object) schema.GroupKind {
@@ -105,6 +217,52 @@ func fullName(name string, groupKind schema.GroupKind) string {
 	return fmt.Sprintf("%s.%s/%s", strings.ToLower(groupKind.Kind), groupKind.Group, name)
 }
 
+// owner returns the controlling owner of the object, or its first owner if there is no controller.
+func owner(acc metav1.Object) string {
+	refs := acc.GetOwnerReferences()
+	if len(refs) == 0 {
+		return "<none>"
+	}
+	for _, ref := range refs {
+		if ref.Controller != nil && *ref.Controller {
+			return ownerName(ref)
+		}
+	}
+	return ownerName(refs[0])
+}
+
+func managers(acc metav1.Object) string {
+	names := util.FieldManagers(acc)
+	if len(names) == 0 {
+		return "<none>"
+	}
+	return strings.Join(names, ",")
+}
+
+// lastUpdated renders the latest time of the managed fields, as age in relative mode and as
+// absolute time otherwise.
+func (p *TablePrinter) lastUpdated(acc metav1.Object) string {
+	latest := util.LastUpdate(acc)
+	if latest == nil {
+		return "<unknown>"
+	}
+	if p.Timestamps == "" || p.Timestamps == TimestampsRelative {
+		return translateTimestampSince(*latest)
+	}
+	return p.absoluteTimestamp(*latest)
+}
+
+// absoluteTimestamp renders the timestamp as RFC3339 in UTC or in the local time zone.
+func (p *TablePrinter) absoluteTimestamp(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	if p.LocalTime {
+		return timestamp.Local().Format(time.RFC3339)
+	}
+	return timestamp.UTC().Format(time.RFC3339)
+}
+
 func translateTimestampSince(timestamp metav1.Time) string {
 	if timestamp.IsZero() {
 		return "<unknown>"
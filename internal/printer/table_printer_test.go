@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newUnstructured(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func TestTablePrinter_ShowManagers(t *testing.T) {
+	o := newUnstructured("apps/v1", "Deployment", "default", "web")
+	o.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kube-controller-manager"},
+		{Manager: "helm"},
+		{Manager: "helm"},
+	})
+	bare := newUnstructured("v1", "ConfigMap", "default", "cfg")
+
+	buffer := &bytes.Buffer{}
+	p := &TablePrinter{ShowManagers: true}
+
+	assert.NoError(t, p.PrintHeader(buffer))
+	assert.NoError(t, p.PrintObj(o, buffer))
+	assert.NoError(t, p.PrintObj(bare, buffer))
+	assert.Equal(t, `NAME	NAMESPACE	AGE	MANAGER
+deployment.apps/web	default	<unknown>	helm,kube-controller-manager	
+configmap/cfg	default	<unknown>	<none>	
+`, buffer.String())
+}
+
+func TestTablePrinter_WideWithLabels(t *testing.T) {
+	isController := true
+	o := newUnstructured("apps/v1", "ReplicaSet", "default", "web-1")
+	o.SetUID("1234")
+	o.SetResourceVersion("42")
+	o.SetLabels(map[string]string{"app.kubernetes.io/name": "web", "tier": "frontend"})
+	o.SetOwnerReferences([]metav1.OwnerReference{
+		{APIVersion: "example.com/v1", Kind: "Other", Name: "x"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: &isController},
+	})
+	bare := newUnstructured("v1", "ConfigMap", "default", "cfg")
+
+	buffer := &bytes.Buffer{}
+	p := &TablePrinter{Wide: true, ShowLabels: true, LabelColumns: []string{"app.kubernetes.io/name", "missing"}}
+
+	assert.NoError(t, p.PrintHeader(buffer))
+	assert.NoError(t, p.PrintObj(o, buffer))
+	assert.NoError(t, p.PrintObj(bare, buffer))
+	assert.Equal(t, `NAME	NAMESPACE	AGE	APIVERSION	OWNER	UID	RESOURCEVERSION	NAME	MISSING	LABELS
+replicaset.apps/web-1	default	<unknown>	apps/v1	deployment.apps/web	1234	42	web		app.kubernetes.io/name=web,tier=frontend	
+configmap/cfg	default	<unknown>	v1	<none>					<none>	
+`, buffer.String())
+}
+
+func TestTablePrinter_Timestamps(t *testing.T) {
+	created := metav1.NewTime(time.Now().Add(-5 * time.Hour).Truncate(time.Second))
+	updated := metav1.NewTime(created.Add(2 * time.Hour))
+	earlier := metav1.NewTime(created.Add(time.Minute))
+	o := newUnstructured("apps/v1", "Deployment", "default", "web")
+	o.SetCreationTimestamp(created)
+	o.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kube-controller-manager", Time: &updated},
+		{Manager: "helm", Time: &earlier},
+	})
+	bare := newUnstructured("v1", "ConfigMap", "default", "cfg")
+
+	buffer := &bytes.Buffer{}
+	p := &TablePrinter{Timestamps: TimestampsBoth, ShowLastUpdated: true}
+
+	assert.NoError(t, p.PrintHeader(buffer))
+	assert.NoError(t, p.PrintObj(o, buffer))
+	assert.NoError(t, p.PrintObj(bare, buffer))
+	assert.Equal(t, `NAME	NAMESPACE	AGE	CREATED	LAST-UPDATED
+deployment.apps/web	default	5h	`+created.UTC().Format(time.RFC3339)+`	`+updated.UTC().Format(time.RFC3339)+`	
+configmap/cfg	default	<unknown>	<unknown>	<unknown>	
+`, buffer.String())
+
+	buffer.Reset()
+	p = &TablePrinter{Timestamps: TimestampsAbsolute, LocalTime: true, NoHeaders: true}
+	assert.NoError(t, p.PrintHeader(buffer))
+	assert.NoError(t, p.PrintObj(o, buffer))
+	assert.Equal(t, "deployment.apps/web\tdefault\t"+created.Local().Format(time.RFC3339)+"\t\n", buffer.String())
+
+	buffer.Reset()
+	p = &TablePrinter{ShowLastUpdated: true}
+	assert.NoError(t, p.PrintObj(o, buffer))
+	assert.Equal(t, "deployment.apps/web\tdefault\t5h\t3h\t\n", buffer.String())
+
+	assert.NoError(t, ValidateTimestamps(""))
+	assert.Error(t, ValidateTimestamps("relatively"))
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TerminatingPrinter reports all objects which are marked for deletion together with
+// their pending finalizers. Objects which have been terminating the longest come first.
+type TerminatingPrinter struct{}
+
+type terminatingObject struct {
+	name       string
+	namespace  string
+	deleted    metav1.Time
+	finalizers []string
+}
+
+func (*TerminatingPrinter) PrintObj(r runtime.Object, w io.Writer) error {
+	items, err := flatten(r)
+	if err != nil {
+		return err
+	}
+
+	var terminating []terminatingObject
+	for _, o := range items {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+		if acc.GetDeletionTimestamp() == nil {
+			continue
+		}
+		terminating = append(terminating, terminatingObject{
+			name:       fullName(acc.GetName(), getObjectGroupKind(o)),
+			namespace:  acc.GetNamespace(),
+			deleted:    *acc.GetDeletionTimestamp(),
+			finalizers: finalizers(o, acc),
+		})
+	}
+
+	if len(terminating) == 0 {
+		_, err := io.WriteString(w, "No terminating resources found.\n")
+		return err
+	}
+
+	sort.SliceStable(terminating, func(i, j int) bool {
+		return terminating[i].deleted.Before(&terminating[j].deleted)
+	})
+
+	tw := tabwriter.NewWriter(w, 4, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", "NAME", "NAMESPACE", "DELETED", "FINALIZERS"); err != nil {
+		return err
+	}
+	for _, t := range terminating {
+		f := "<none>"
+		if len(t.finalizers) > 0 {
+			f = strings.Join(t.finalizers, ",")
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", t.name, t.namespace, translateTimestampSince(t.deleted), f); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// finalizers returns the finalizers which block the deletion of the object. Besides the
+// metadata finalizers, this includes the spec finalizers of namespaces.
+func finalizers(o runtime.Object, acc metav1.Object) []string {
+	ret := append([]string(nil), acc.GetFinalizers()...)
+	if getObjectGroupKind(o).String() != "Namespace" {
+		return ret
+	}
+	if u, ok := o.(*unstructured.Unstructured); ok {
+		specFinalizers, _, _ := unstructured.NestedStringSlice(u.Object, "spec", "finalizers")
+		ret = append(ret, specFinalizers...)
+	}
+	return ret
+}
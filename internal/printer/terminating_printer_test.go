@@ -0,0 +1,66 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestTerminatingPrinter_PrintObj(t *testing.T) {
+	now := time.Now()
+
+	ns := newUnstructured("v1", "Namespace", "", "stuck")
+	ns.SetDeletionTimestamp(&metav1.Time{Time: now.Add(-2 * time.Hour)})
+	_ = unstructured.SetNestedStringSlice(ns.Object, []string{"kubernetes"}, "spec", "finalizers")
+
+	cr := newUnstructured("example.com/v1", "Widget", "stuck", "w")
+	cr.SetDeletionTimestamp(&metav1.Time{Time: now.Add(-3 * time.Hour)})
+	cr.SetFinalizers([]string{"example.com/cleanup"})
+
+	pod := newUnstructured("v1", "Pod", "stuck", "p")
+	pod.SetDeletionTimestamp(&metav1.Time{Time: now.Add(-time.Minute)})
+
+	alive := newUnstructured("v1", "ConfigMap", "default", "alive")
+
+	buffer := &bytes.Buffer{}
+	p := &TerminatingPrinter{}
+	err := p.PrintObj(util.ToV1List([]runtime.Object{alive, ns, util.ToV1List([]runtime.Object{pod, cr})}), buffer)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `NAME                  NAMESPACE  DELETED  FINALIZERS
+widget.example.com/w  stuck      3h       example.com/cleanup
+namespace/stuck                  120m     kubernetes
+pod/p                 stuck      60s      <none>
+`, buffer.String())
+}
+
+func TestTerminatingPrinter_Empty(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	p := &TerminatingPrinter{}
+	err := p.PrintObj(util.ToV1List([]runtime.Object{newUnstructured("v1", "ConfigMap", "default", "alive")}), buffer)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "No terminating resources found.\n", buffer.String())
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TreePrinter renders the objects as a forest along their owner references, for example
+// Deployment → ReplicaSet → Pod. Objects without owners are the roots, cluster-scoped roots
+// come first. Objects whose owners were not fetched are shown as roots, together with the
+// missing owner. Objects in an owner reference cycle are shown as roots after all others.
+type TreePrinter struct{}
+
+type treeNode struct {
+	object   runtime.Object
+	acc      metav1.Object
+	children []*treeNode
+	owner    *treeNode // the first owner which was fetched
+}
+
+func (*TreePrinter) PrintObj(r runtime.Object, w io.Writer) error {
+	items, err := flatten(r)
+	if err != nil {
+		return err
+	}
+
+	nodes := make([]*treeNode, 0, len(items))
+	byUID := map[types.UID]*treeNode{}
+	for _, o := range items {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+		n := &treeNode{object: o, acc: acc}
+		nodes = append(nodes, n)
+		if acc.GetUID() != "" {
+			byUID[acc.GetUID()] = n
+		}
+	}
+
+	var clusterRoots, namespacedRoots []*treeNode
+	missingOwners := map[*treeNode][]string{}
+	for _, n := range nodes {
+		hasOwner := false
+		for _, ref := range n.acc.GetOwnerReferences() {
+			owner, ok := byUID[ref.UID]
+			if !ok || owner == n {
+				missingOwners[n] = append(missingOwners[n], ownerName(ref))
+				continue
+			}
+			owner.children = append(owner.children, n)
+			if !hasOwner {
+				n.owner = owner
+			}
+			hasOwner = true
+		}
+		if hasOwner {
+			continue
+		}
+		if n.acc.GetNamespace() == "" {
+			clusterRoots = append(clusterRoots, n)
+		} else {
+			namespacedRoots = append(namespacedRoots, n)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 4, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", "NAME", "NAMESPACE", "AGE"); err != nil {
+		return err
+	}
+	printed := map[*treeNode]bool{}
+	for _, root := range append(clusterRoots, namespacedRoots...) {
+		name := fullName(root.acc.GetName(), getObjectGroupKind(root.object))
+		if missing := missingOwners[root]; len(missing) > 0 {
+			name = fmt.Sprintf("%s (owner %s not found)", name, strings.Join(missing, ","))
+		}
+		if err := printTree(tw, root, name, printed); err != nil {
+			return err
+		}
+	}
+
+	// In an owner reference cycle every object has an owner, so none of them is a root.
+	for _, n := range nodes {
+		if printed[n] {
+			continue
+		}
+		n = cycleMember(n)
+		name := fullName(n.acc.GetName(), getObjectGroupKind(n.object)) + " (owner cycle)"
+		if err := printTree(tw, n, name, printed); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// cycleMember follows the owners of n, which must end in a cycle, and returns a member of it.
+func cycleMember(n *treeNode) *treeNode {
+	seen := map[*treeNode]bool{}
+	for !seen[n] {
+		seen[n] = true
+		n = n.owner
+	}
+	return n
+}
+
+// printTree prints the root with the given name and its subtree, and records the printed nodes.
+func printTree(w io.Writer, root *treeNode, name string, printed map[*treeNode]bool) error {
+	if err := printTreeRow(w, root, name); err != nil {
+		return err
+	}
+	printed[root] = true
+	return printSubtree(w, root, "", map[*treeNode]bool{root: true}, printed)
+}
+
+// printSubtree prints the children of n. The visited nodes are the ancestors of n, which are
+// skipped to break owner reference cycles.
+func printSubtree(w io.Writer, n *treeNode, prefix string, visited, printed map[*treeNode]bool) error {
+	children := make([]*treeNode, 0, len(n.children))
+	for _, child := range n.children {
+		if !visited[child] {
+			children = append(children, child)
+		}
+	}
+
+	for i, child := range children {
+		branch, indent := "├─", "│ "
+		if i == len(children)-1 {
+			branch, indent = "└─", "  "
+		}
+		name := prefix + branch + fullName(child.acc.GetName(), getObjectGroupKind(child.object))
+		if err := printTreeRow(w, child, name); err != nil {
+			return err
+		}
+		printed[child] = true
+
+		visited[child] = true
+		if err := printSubtree(w, child, prefix+indent, visited, printed); err != nil {
+			return err
+		}
+		delete(visited, child)
+	}
+	return nil
+}
+
+func printTreeRow(w io.Writer, n *treeNode, name string) error {
+	_, err := fmt.Fprintf(w, "%s\t%s\t%s\n", name, n.acc.GetNamespace(), translateTimestampSince(n.acc.GetCreationTimestamp()))
+	return err
+}
+
+func ownerName(ref metav1.OwnerReference) string {
+	gv, _ := schema.ParseGroupVersion(ref.APIVersion)
+	return fullName(ref.Name, schema.GroupKind{Group: gv.Group, Kind: ref.Kind})
+}
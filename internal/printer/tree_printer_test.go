@@ -0,0 +1,98 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newOwnedObj(apiVersion, kind, namespace, name string, owners ...*unstructured.Unstructured) *unstructured.Unstructured {
+	o := newUnstructured(apiVersion, kind, namespace, name)
+	o.SetUID(types.UID(kind + "/" + name))
+	var refs []metav1.OwnerReference
+	for _, owner := range owners {
+		refs = append(refs, metav1.OwnerReference{
+			APIVersion: owner.GetAPIVersion(),
+			Kind:       owner.GetKind(),
+			Name:       owner.GetName(),
+			UID:        owner.GetUID(),
+		})
+	}
+	o.SetOwnerReferences(refs)
+	return o
+}
+
+func TestTreePrinter_PrintObj(t *testing.T) {
+	deploy := newOwnedObj("apps/v1", "Deployment", "default", "web")
+	rs := newOwnedObj("apps/v1", "ReplicaSet", "default", "web-1", deploy)
+	pod1 := newOwnedObj("v1", "Pod", "default", "web-1-a", rs)
+	pod2 := newOwnedObj("v1", "Pod", "default", "web-1-b", rs)
+	missing := newOwnedObj("batch/v1", "Job", "default", "gone")
+	orphan := newOwnedObj("v1", "Pod", "default", "job-x", missing)
+	node := newOwnedObj("v1", "Node", "", "node-1")
+	lease := newOwnedObj("coordination.k8s.io/v1", "Lease", "kube-node-lease", "node-1", node)
+
+	objects := util.ToV1List([]runtime.Object{pod1, deploy, orphan, lease, util.ToV1List([]runtime.Object{rs, pod2}), node})
+
+	buffer := &bytes.Buffer{}
+	p := &TreePrinter{}
+	err := p.PrintObj(objects, buffer)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `NAME                                        NAMESPACE        AGE
+node/node-1                                                  <unknown>
+└─lease.coordination.k8s.io/node-1          kube-node-lease  <unknown>
+deployment.apps/web                         default          <unknown>
+└─replicaset.apps/web-1                     default          <unknown>
+  ├─pod/web-1-a                             default          <unknown>
+  └─pod/web-1-b                             default          <unknown>
+pod/job-x (owner job.batch/gone not found)  default          <unknown>
+`, buffer.String())
+}
+
+func TestTreePrinter_OwnerCycle(t *testing.T) {
+	a := newOwnedObj("v1", "ConfigMap", "default", "a")
+	b := newOwnedObj("v1", "ConfigMap", "default", "b", a)
+	c := newOwnedObj("v1", "ConfigMap", "default", "c", b)
+	d := newOwnedObj("v1", "ConfigMap", "default", "d", a)
+	a = newOwnedObj("v1", "ConfigMap", "default", "a", b)
+	root := newOwnedObj("v1", "ConfigMap", "default", "root")
+
+	// b is the last child of a, but is skipped as the ancestor of a
+	objects := util.ToV1List([]runtime.Object{c, d, a, b, root})
+
+	buffer := &bytes.Buffer{}
+	p := &TreePrinter{}
+	err := p.PrintObj(objects, buffer)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `NAME                       NAMESPACE  AGE
+configmap/root             default    <unknown>
+configmap/b (owner cycle)  default    <unknown>
+├─configmap/c              default    <unknown>
+└─configmap/a              default    <unknown>
+  └─configmap/d            default    <unknown>
+`, buffer.String())
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/corneliusweig/ketall/internal/client"
+	"github.com/corneliusweig/ketall/internal/constants"
+	"github.com/corneliusweig/ketall/internal/options"
+	"github.com/corneliusweig/ketall/internal/printer"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+)
+
+// phases in which the manifests are applied, so that each object finds its dependencies
+const (
+	phaseCRDs = iota
+	phaseNamespaces
+	phaseRBAC
+	phaseConfig
+	phaseWorkloads
+	phaseCustomResources
+)
+
+var (
+	crdGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+	crdResource  = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+	rbacKinds   = sets.NewString("ServiceAccount")
+	configKinds = sets.NewString(
+		"ConfigMap", "Secret", "LimitRange", "ResourceQuota", "PersistentVolume", "PersistentVolumeClaim",
+		"StorageClass.storage.k8s.io", "PriorityClass.scheduling.k8s.io",
+	)
+)
+
+// Restore server-side applies all manifests below the given directory in dependency order and
+// reports the result for each object. Failed objects do not stop the restore, but are reported
+// in the returned error.
+func Restore(ctx context.Context, o *options.RestoreOptions) error {
+	if o.DryRun != options.DryRunNone && o.DryRun != options.DryRunServer {
+		return fmt.Errorf("%s is not a valid dry run mode (must be one of '%s' or '%s')", o.DryRun, options.DryRunNone, options.DryRunServer)
+	}
+
+	objects, err := loadManifests(o.Dir)
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return fmt.Errorf("no manifests found in %s", o.Dir)
+	}
+	phases := sortByPhase(objects)
+
+	mapper, err := client.NewRESTMapper(o.GenericCliFlags, false)
+	if err != nil {
+		return err
+	}
+	config, err := o.GenericCliFlags.ToRESTConfig()
+	if err != nil {
+		return errors.Wrap(err, "rest config")
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "dynamic client")
+	}
+	namespace, _, err := o.GenericCliFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return errors.Wrap(err, "default namespace")
+	}
+
+	r := &restorer{
+		options:   o,
+		mapper:    mapper,
+		client:    dynamicClient,
+		namespace: namespace,
+		crdKinds:  crdKinds(objects),
+	}
+	return r.restore(ctx, objects, phases)
+}
+
+// resettableMapper maps kinds to resources, and can be reset to pick up newly established CRDs.
+type resettableMapper interface {
+	meta.RESTMapper
+	Reset()
+}
+
+type restorer struct {
+	options   *options.RestoreOptions
+	mapper    resettableMapper
+	client    dynamic.Interface
+	namespace string
+	// crdKinds are the kinds which are defined by the CRDs among the restored objects
+	crdKinds sets.String
+}
+
+// errCRDNotInstalled is returned in a server dry run for custom resources whose CRD is restored
+// as well, because the server dry run does not create the CRD.
+var errCRDNotInstalled = errors.New("CRD not installed")
+
+// restore applies the objects, which are sorted by phase.
+func (r *restorer) restore(ctx context.Context, objects []*unstructured.Unstructured, phases map[*unstructured.Unstructured]int) error {
+	var failed int
+	var crds []string
+	for _, u := range objects {
+		if phases[u] > phaseCRDs && len(crds) > 0 {
+			// the custom resources can only be mapped once their CRDs are served
+			if r.options.DryRun == options.DryRunNone {
+				r.waitEstablished(ctx, crds)
+			}
+			r.mapper.Reset()
+			crds = nil
+		}
+
+		err := r.apply(ctx, u)
+		if errors.Is(err, errCRDNotInstalled) {
+			fmt.Fprintf(r.options.Streams.Out, "%s skipped (%s)%s\n", resultName(u), err, r.dryRunSuffix())
+			continue
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(r.options.Streams.ErrOut, "%s failed: %s\n", resultName(u), err)
+			continue
+		}
+		fmt.Fprintf(r.options.Streams.Out, "%s serverside-applied%s\n", resultName(u), r.dryRunSuffix())
+		if u.GroupVersionKind().GroupKind() == crdGroupKind {
+			crds = append(crds, u.GetName())
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d objects could not be restored", failed, len(objects))
+	}
+	return nil
+}
+
+func (r *restorer) apply(ctx context.Context, u *unstructured.Unstructured) error {
+	if printer.IsRedacted(u.Object) {
+		return fmt.Errorf("contains redacted values, export with --%s", constants.FlagShowSecrets)
+	}
+
+	gvk := u.GroupVersionKind()
+	mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		if r.options.DryRun == options.DryRunServer && meta.IsNoMatchError(err) && r.crdKinds.Has(gvk.GroupKind().String()) {
+			return errCRDNotInstalled
+		}
+		return errors.Wrap(err, "map kind to resource")
+	}
+
+	var resource dynamic.ResourceInterface = r.client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if u.GetNamespace() == "" {
+			u.SetNamespace(r.namespace)
+		}
+		resource = r.client.Resource(mapping.Resource).Namespace(u.GetNamespace())
+	}
+
+	data, err := json.Marshal(u.Object)
+	if err != nil {
+		return err
+	}
+	patchOptions := metav1.PatchOptions{
+		FieldManager: r.options.FieldManager,
+		Force:        &r.options.ForceConflicts,
+	}
+	if r.options.DryRun == options.DryRunServer {
+		patchOptions.DryRun = []string{metav1.DryRunAll}
+	}
+	_, err = resource.Patch(ctx, u.GetName(), types.ApplyPatchType, data, patchOptions)
+	return err
+}
+
+// waitEstablished waits until the given CRDs are established or the timeout expires.
+func (r *restorer) waitEstablished(ctx context.Context, names []string) {
+	pending := sets.NewString(names...)
+	err := wait.PollImmediate(time.Second, r.options.Timeout, func() (bool, error) {
+		for _, name := range pending.List() {
+			crd, err := r.client.Resource(crdResource).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				klog.V(2).Infof("Cannot get CRD %s: %s", name, err)
+				continue
+			}
+			if established(crd) {
+				pending.Delete(name)
+			}
+		}
+		return pending.Len() == 0, nil
+	})
+	if err != nil {
+		klog.Warningf("CRDs not established after %s, their custom resources will likely fail: %s", r.options.Timeout, strings.Join(pending.List(), ", "))
+	}
+}
+
+func (r *restorer) dryRunSuffix() string {
+	if r.options.DryRun == options.DryRunServer {
+		return " (server dry run)"
+	}
+	return ""
+}
+
+func established(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// loadManifests reads all yaml and json files below dir. Lists are expanded into their items
+// and kustomization files are skipped.
+func loadManifests(dir string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		decoded, err := decode(file)
+		if err != nil {
+			return errors.Wrapf(err, "read manifests from %s", path)
+		}
+		objects = append(objects, decoded...)
+		return nil
+	})
+	return objects, err
+}
+
+func decode(r io.Reader) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if err == io.EOF {
+				return objects, nil
+			}
+			return nil, err
+		}
+		if len(u.Object) == 0 || u.GetKind() == "Kustomization" {
+			continue
+		}
+		if !u.IsList() {
+			objects = append(objects, u)
+			continue
+		}
+		err := u.EachListItem(func(item runtime.Object) error {
+			objects = append(objects, item.(*unstructured.Unstructured))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sortByPhase orders the objects by their phase and keeps the order of objects in the same phase.
+func sortByPhase(objects []*unstructured.Unstructured) map[*unstructured.Unstructured]int {
+	custom := crdKinds(objects)
+	phases := make(map[*unstructured.Unstructured]int, len(objects))
+	for _, u := range objects {
+		phases[u] = phase(u.GroupVersionKind().GroupKind(), custom)
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return phases[objects[i]] < phases[objects[j]]
+	})
+	return phases
+}
+
+// crdKinds returns the kinds which are defined by the CRDs among the objects, like kind.group.
+func crdKinds(objects []*unstructured.Unstructured) sets.String {
+	kinds := sets.NewString()
+	for _, u := range objects {
+		if u.GroupVersionKind().GroupKind() == crdGroupKind {
+			group, _, _ := unstructured.NestedString(u.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(u.Object, "spec", "names", "kind")
+			kinds.Insert(schema.GroupKind{Group: group, Kind: kind}.String())
+		}
+	}
+	return kinds
+}
+
+func phase(groupKind schema.GroupKind, custom sets.String) int {
+	switch {
+	case groupKind == crdGroupKind:
+		return phaseCRDs
+	case groupKind.String() == "Namespace":
+		return phaseNamespaces
+	case groupKind.Group == "rbac.authorization.k8s.io" || rbacKinds.Has(groupKind.String()):
+		return phaseRBAC
+	case configKinds.Has(groupKind.String()):
+		return phaseConfig
+	case custom.Has(groupKind.String()) || !builtin(groupKind.Group):
+		return phaseCustomResources
+	}
+	return phaseWorkloads
+}
+
+// builtin tells if the API group is part of Kubernetes, such as apps or networking.k8s.io.
+func builtin(group string) bool {
+	return !strings.Contains(group, ".") || strings.HasSuffix(group, ".k8s.io")
+}
+
+// resultName formats the object like kind.group/name, followed by its namespace.
+func resultName(u *unstructured.Unstructured) string {
+	groupKind := u.GroupVersionKind().GroupKind()
+	name := strings.ToLower(groupKind.Kind)
+	if groupKind.Group != "" {
+		name += "." + groupKind.Group
+	}
+	name += "/" + u.GetName()
+	if u.GetNamespace() != "" {
+		name += " (" + u.GetNamespace() + ")"
+	}
+	return name
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/options"
+	"github.com/corneliusweig/ketall/internal/printer"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestLoadManifests(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "default"), 0755))
+	files := map[string]string{
+		"kustomization.yaml": "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n- default\n",
+		"README.md":          "not a manifest",
+		"Namespace-default.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+`,
+		"default/all.yaml": `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: cfg
+`,
+		"default/Secret-token.json": `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"token","namespace":"default"}}`,
+	}
+	for name, content := range files {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	objects, err := loadManifests(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, u := range objects {
+		names = append(names, resultName(u))
+	}
+	assert.Equal(t, []string{"namespace/default", "secret/token (default)", "deployment.apps/web (default)", "configmap/cfg"}, names)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("kind: [\n"), 0644))
+	_, err = loadManifests(dir)
+	assert.Error(t, err)
+}
+
+func TestSortByPhase(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		newUnstructured("example.com/v1", "Widget", "default", "w"),
+		newUnstructured("apps/v1", "Deployment", "default", "web"),
+		newUnstructured("v1", "ConfigMap", "default", "cfg"),
+		newUnstructured("rbac.authorization.k8s.io/v1", "RoleBinding", "default", "rb"),
+		newUnstructured("snapshot.storage.k8s.io/v1", "VolumeSnapshot", "default", "snap"),
+		newUnstructured("v1", "ServiceAccount", "default", "sa"),
+		newUnstructured("v1", "Namespace", "", "default"),
+		newUnstructured("networking.k8s.io/v1", "Ingress", "default", "web"),
+		newUnstructured("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "volumesnapshots.snapshot.storage.k8s.io"),
+	}
+	_ = unstructured.SetNestedField(objects[8].Object, "snapshot.storage.k8s.io", "spec", "group")
+	_ = unstructured.SetNestedField(objects[8].Object, "VolumeSnapshot", "spec", "names", "kind")
+
+	phases := sortByPhase(objects)
+
+	var names []string
+	for _, u := range objects {
+		names = append(names, resultName(u))
+	}
+	assert.Equal(t, []string{
+		"customresourcedefinition.apiextensions.k8s.io/volumesnapshots.snapshot.storage.k8s.io",
+		"namespace/default",
+		"rolebinding.rbac.authorization.k8s.io/rb (default)",
+		"serviceaccount/sa (default)",
+		"configmap/cfg (default)",
+		"deployment.apps/web (default)",
+		"ingress.networking.k8s.io/web (default)",
+		"widget.example.com/w (default)",
+		"volumesnapshot.snapshot.storage.k8s.io/snap (default)",
+	}, names)
+	assert.Equal(t, phaseCustomResources, phases[objects[8]])
+}
+
+func TestEstablished(t *testing.T) {
+	crd := newUnstructured("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "widgets.example.com")
+	assert.False(t, established(crd))
+
+	_ = unstructured.SetNestedSlice(crd.Object, []interface{}{
+		map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+		map[string]interface{}{"type": "Established", "status": "True"},
+	}, "status", "conditions")
+	assert.True(t, established(crd))
+}
+
+func TestRestore_InvalidOptions(t *testing.T) {
+	o := options.NewRestoreOptions()
+	o.Dir = t.TempDir()
+
+	o.DryRun = "client"
+	assert.Error(t, Restore(context.Background(), o))
+
+	o.DryRun = options.DryRunServer
+	err := Restore(context.Background(), o)
+	assert.EqualError(t, err, "no manifests found in "+o.Dir)
+}
+
+func TestApply_Redacted(t *testing.T) {
+	secret := newUnstructured("v1", "Secret", "default", "token")
+	_ = unstructured.SetNestedField(secret.Object, printer.RedactedPrefix+"0123456789abcdef", "data", "token")
+
+	r := &restorer{options: options.NewRestoreOptions()}
+	assert.EqualError(t, r.apply(context.Background(), secret), "contains redacted values, export with --show-secrets")
+}
+
+func newUnstructured(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+// staticMapper maps a fixed set of kinds and counts the resets.
+type staticMapper struct {
+	*meta.DefaultRESTMapper
+	resets int
+}
+
+func (m *staticMapper) Reset() {
+	m.resets++
+}
+
+func newStaticMapper() *staticMapper {
+	m := meta.NewDefaultRESTMapper(nil)
+	m.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	m.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	m.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	m.Add(crdGroupKind.WithVersion("v1"), meta.RESTScopeRoot)
+	return &staticMapper{DefaultRESTMapper: m}
+}
+
+// newRestorer returns a restorer whose client records the applied objects as
+// resource/namespace/name, and fails to apply objects named "broken".
+func newRestorer(t *testing.T, dryRun string, objects []*unstructured.Unstructured) (*restorer, *[]string, *bytes.Buffer, *bytes.Buffer) {
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	o := options.NewRestoreOptions()
+	o.Streams = &streams
+	o.DryRun = dryRun
+	o.FieldManager = "ketall"
+
+	var applied []string
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		assert.Equal(t, types.ApplyPatchType, patch.GetPatchType())
+		if patch.GetName() == "broken" {
+			return true, nil, errors.New("admission webhook denied the request")
+		}
+		applied = append(applied, patch.GetResource().Resource+"/"+patch.GetNamespace()+"/"+patch.GetName())
+		u := &unstructured.Unstructured{}
+		return true, u, u.UnmarshalJSON(patch.GetPatch())
+	})
+
+	return &restorer{
+		options:   o,
+		mapper:    newStaticMapper(),
+		client:    client,
+		namespace: "team",
+		crdKinds:  crdKinds(objects),
+	}, &applied, out, errOut
+}
+
+func TestRestorer_Restore(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		newUnstructured("apps/v1", "Deployment", "prod", "web"),
+		newUnstructured("v1", "ConfigMap", "", "cfg"),
+		newUnstructured("v1", "ConfigMap", "prod", "broken"),
+		newUnstructured("v1", "Namespace", "", "prod"),
+		newUnstructured("example.com/v1", "Gadget", "prod", "unknown"),
+	}
+	phases := sortByPhase(objects)
+	r, applied, out, errOut := newRestorer(t, options.DryRunNone, objects)
+
+	err := r.restore(context.Background(), objects, phases)
+	assert.EqualError(t, err, "2 of 5 objects could not be restored")
+	assert.Equal(t, []string{"namespaces//prod", "configmaps/team/cfg", "deployments/prod/web"}, *applied)
+	assert.Equal(t, `namespace/prod serverside-applied
+configmap/cfg (team) serverside-applied
+deployment.apps/web (prod) serverside-applied
+`, out.String())
+	assert.Contains(t, errOut.String(), "configmap/broken (prod) failed: admission webhook denied the request\n")
+	assert.Contains(t, errOut.String(), "gadget.example.com/unknown (prod) failed: map kind to resource")
+}
+
+func TestRestorer_RestoreServerDryRun(t *testing.T) {
+	crd := newUnstructured("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "widgets.example.com")
+	_ = unstructured.SetNestedField(crd.Object, "example.com", "spec", "group")
+	_ = unstructured.SetNestedField(crd.Object, "Widget", "spec", "names", "kind")
+	objects := []*unstructured.Unstructured{
+		newUnstructured("example.com/v1", "Widget", "prod", "blue"),
+		newUnstructured("example.com/v1", "Gadget", "prod", "unknown"),
+		crd,
+	}
+	phases := sortByPhase(objects)
+	r, applied, out, errOut := newRestorer(t, options.DryRunServer, objects)
+
+	err := r.restore(context.Background(), objects, phases)
+	assert.EqualError(t, err, "1 of 3 objects could not be restored")
+	assert.Equal(t, []string{"customresourcedefinitions//widgets.example.com"}, *applied)
+	assert.Equal(t, `customresourcedefinition.apiextensions.k8s.io/widgets.example.com serverside-applied (server dry run)
+widget.example.com/blue (prod) skipped (CRD not installed) (server dry run)
+`, out.String())
+	assert.Contains(t, errOut.String(), "gadget.example.com/unknown (prod) failed: map kind to resource")
+	assert.Equal(t, 1, r.mapper.(*staticMapper).resets)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// special keys, all other keys are represented by their character
+const (
+	keyUp        = "<up>"
+	keyDown      = "<down>"
+	keyPageUp    = "<pgup>"
+	keyPageDown  = "<pgdown>"
+	keyHome      = "<home>"
+	keyEnd       = "<end>"
+	keyEnter     = "<enter>"
+	keyEsc       = "<esc>"
+	keyBackspace = "<backspace>"
+	keyCtrlC     = "<ctrl-c>"
+)
+
+// escapeSequences maps the input of special keys in raw mode to their key
+var escapeSequences = map[string]string{
+	"\x1b[A":  keyUp,
+	"\x1bOA":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1bOB":  keyDown,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+	"\x1b[H":  keyHome,
+	"\x1b[1~": keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1b[4~": keyEnd,
+}
+
+// parseKeys splits the input read from a terminal in raw mode into keys. Unknown escape
+// sequences are dropped.
+func parseKeys(input []byte) []string {
+	var keys []string
+	s := string(input)
+	for len(s) > 0 {
+		switch s[0] {
+		case '\r', '\n':
+			keys, s = append(keys, keyEnter), s[1:]
+			continue
+		case 0x7f, '\b':
+			keys, s = append(keys, keyBackspace), s[1:]
+			continue
+		case 0x03:
+			keys, s = append(keys, keyCtrlC), s[1:]
+			continue
+		case 0x1b:
+			if len(s) == 1 {
+				keys, s = append(keys, keyEsc), ""
+				continue
+			}
+			matched := false
+			for sequence, key := range escapeSequences {
+				if strings.HasPrefix(s, sequence) {
+					keys, s, matched = append(keys, key), s[len(sequence):], true
+					break
+				}
+			}
+			if !matched {
+				// skip the unknown sequence up to its final byte
+				end := 2
+				for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+					end++
+				}
+				if end < len(s) {
+					end++
+				}
+				s = s[end:]
+			}
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s)
+		if r >= ' ' {
+			keys = append(keys, string(r))
+		}
+		s = s[size:]
+	}
+	return keys
+}
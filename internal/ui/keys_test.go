@@ -0,0 +1,45 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  []string
+	}{
+		"characters":       {"/wé", []string{"/", "w", "é"}},
+		"arrows":           {"\x1b[A\x1b[Bj", []string{keyUp, keyDown, "j"}},
+		"application mode": {"\x1bOA", []string{keyUp}},
+		"pages":            {"\x1b[5~\x1b[6~", []string{keyPageUp, keyPageDown}},
+		"escape":           {"\x1b", []string{keyEsc}},
+		"control":          {"a\r\x7f\x03", []string{"a", keyEnter, keyBackspace, keyCtrlC}},
+		"unknown sequence": {"\x1b[1;5Cq", []string{"q"}},
+		"other control":    {"\x01x", []string{"x"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, parseKeys([]byte(test.input)))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+)
+
+// logBuffer collects the klog messages while the user interface is shown, because they would
+// be written over the screen otherwise. The messages are already formatted by klog.
+type logBuffer struct {
+	mu       sync.Mutex // mu guards messages
+	messages []string
+}
+
+var _ logr.LogSink = &logBuffer{}
+
+func (b *logBuffer) Init(logr.RuntimeInfo) {}
+
+// Enabled is always true, because klog checks the verbosity before passing messages on.
+func (b *logBuffer) Enabled(int) bool {
+	return true
+}
+
+func (b *logBuffer) Info(_ int, msg string, keysAndValues ...interface{}) {
+	b.add(msg, keysAndValues...)
+}
+
+func (b *logBuffer) Error(err error, msg string, keysAndValues ...interface{}) {
+	b.add(msg, append([]interface{}{"err", err}, keysAndValues...)...)
+}
+
+func (b *logBuffer) WithValues(...interface{}) logr.LogSink {
+	return b
+}
+
+func (b *logBuffer) WithName(string) logr.LogSink {
+	return b
+}
+
+// add appends the message with the key/value pairs of structured logging, as klog would format
+// them, such as 'Cannot fetch resource="secrets" err="forbidden"'.
+func (b *logBuffer) add(msg string, keysAndValues ...interface{}) {
+	var line strings.Builder
+	line.WriteString(strings.TrimSuffix(msg, "\n"))
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		if s, ok := value.(string); ok {
+			fmt.Fprintf(&line, " %v=%q", keysAndValues[i], s)
+		} else {
+			fmt.Fprintf(&line, " %v=%+v", keysAndValues[i], value)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, line.String())
+}
+
+// WriteTo writes the collected messages, one per line.
+func (b *logBuffer) WriteTo(w io.Writer) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var written int64
+	for _, msg := range b.messages {
+		n, err := io.WriteString(w, msg+"\n")
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/client"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/klog/v2"
+)
+
+func TestLogBuffer(t *testing.T) {
+	logs := &logBuffer{}
+	klog.SetLogger(logr.New(logs))
+	klog.Warningf("Cannot fetch: %s", "secrets")
+	klog.Errorf("broken")
+	klog.V(10).Infof("not shown")
+	klog.ClearLogger()
+
+	buffer := &bytes.Buffer{}
+	_, err := logs.WriteTo(buffer)
+	assert.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), "Cannot fetch: secrets")
+	assert.Contains(t, string(lines[1]), "broken")
+}
+
+func TestLogBuffer_StructuredLogging(t *testing.T) {
+	logs := &logBuffer{}
+	klog.SetLogger(logr.New(logs))
+	klog.ErrorS(errors.New("forbidden"), "Cannot fetch", "resource", "secrets", "attempts", 2)
+	klog.InfoS("Fetched", "resources", 3)
+	klog.ClearLogger()
+
+	buffer := &bytes.Buffer{}
+	_, err := logs.WriteTo(buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "Cannot fetch err=\"forbidden\" resource=\"secrets\" attempts=2\nFetched resources=3\n", buffer.String())
+}
+
+func TestIncompleteStatus(t *testing.T) {
+	forbidden := client.ResourceError{Resource: "secrets", Err: errors.New("forbidden")}
+	assert.Equal(t, "1 resource could not be fetched", incompleteStatus([]client.ResourceError{forbidden}))
+	assert.Equal(t, "2 resources could not be fetched", incompleteStatus([]client.ResourceError{forbidden, forbidden}))
+}
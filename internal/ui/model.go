@@ -0,0 +1,386 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/corneliusweig/ketall/internal/printer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// escape codes for the terminal
+const (
+	reverse = "\x1b[7m"
+	bold    = "\x1b[1m"
+	reset   = "\x1b[0m"
+)
+
+const listHelp = "↑/↓ move  / search  n namespace  t kind  c clear  enter yaml  r refresh  q quit"
+const detailHelp = "↑/↓ scroll  enter/esc back  q quit"
+
+// entry is an object together with its rendered table row.
+type entry struct {
+	object    runtime.Object
+	row       string
+	namespace string
+	kind      string
+}
+
+// model holds the state of the user interface. It does not depend on a terminal, so that the
+// key handling and rendering can be tested.
+type model struct {
+	header  string
+	entries []entry
+	// visible holds the indexes of the entries which pass the filters
+	visible []int
+
+	namespaces []string
+	kinds      []string
+	namespace  string
+	kind       string
+	search     string
+	searching  bool
+
+	// cursor is the selected line of visible, offset the first visible line on screen
+	cursor int
+	offset int
+
+	// detail focuses the YAML pane, which is scrolled by detailOffset
+	detail       bool
+	detailOffset int
+	yaml         func(runtime.Object) (string, error)
+	yamlCache    map[runtime.Object]string
+
+	status string
+}
+
+// action tells the main loop what to do after a key was handled.
+type action int
+
+const (
+	actionNone action = iota
+	actionQuit
+	actionRefresh
+)
+
+func newModel(yaml func(runtime.Object) (string, error)) *model {
+	return &model{yaml: yaml}
+}
+
+// setObjects replaces the objects and keeps the filters and the selection, if possible.
+func (m *model) setObjects(table *printer.TablePrinter, objects []runtime.Object) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 4, 4, 2, ' ', 0)
+	if err := table.PrintHeader(tw); err != nil {
+		return err
+	}
+	for _, o := range objects {
+		if err := table.PrintObj(o, tw); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if table.NoHeaders {
+		lines = append([]string{""}, lines...)
+	}
+
+	namespaces, kinds := sets.NewString(), sets.NewString()
+	m.header = strings.TrimRight(lines[0], " ")
+	m.entries = make([]entry, len(objects))
+	for i, o := range objects {
+		acc, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+		e := entry{
+			object:    o,
+			row:       strings.TrimRight(lines[i+1], " "),
+			namespace: acc.GetNamespace(),
+			kind:      o.GetObjectKind().GroupVersionKind().GroupKind().String(),
+		}
+		if e.namespace != "" {
+			namespaces.Insert(e.namespace)
+		}
+		kinds.Insert(e.kind)
+		m.entries[i] = e
+	}
+	m.namespaces, m.kinds = namespaces.List(), kinds.List()
+	m.yamlCache = map[runtime.Object]string{}
+	m.applyFilters()
+	return nil
+}
+
+func (m *model) applyFilters() {
+	search := strings.ToLower(m.search)
+	m.visible = m.visible[:0]
+	for i, e := range m.entries {
+		if m.namespace != "" && e.namespace != m.namespace {
+			continue
+		}
+		if m.kind != "" && e.kind != m.kind {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(e.row), search) {
+			continue
+		}
+		m.visible = append(m.visible, i)
+	}
+	m.moveCursor(0)
+}
+
+// moveCursor moves the selection by delta lines and keeps it within the visible entries.
+func (m *model) moveCursor(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.visible) {
+		m.cursor = len(m.visible) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	m.detailOffset = 0
+}
+
+// selected returns the selected object, or nil if no object passes the filters.
+func (m *model) selected() runtime.Object {
+	if len(m.visible) == 0 {
+		return nil
+	}
+	return m.entries[m.visible[m.cursor]].object
+}
+
+// next returns the value after current in values, where the empty string stands for all values.
+func next(values []string, current string) string {
+	i := sort.SearchStrings(values, current)
+	if current == "" {
+		i = -1
+	}
+	if i+1 >= len(values) {
+		return ""
+	}
+	return values[i+1]
+}
+
+// handle updates the model for the given key, page is the number of lines of a page.
+func (m *model) handle(key string, page int) action {
+	m.status = ""
+	if key == keyCtrlC {
+		return actionQuit
+	}
+
+	if m.searching {
+		switch key {
+		case keyEnter:
+			m.searching = false
+		case keyEsc:
+			m.searching = false
+			m.search = ""
+		case keyBackspace:
+			if r := []rune(m.search); len(r) > 0 {
+				m.search = string(r[:len(r)-1])
+			}
+		case keyUp, keyDown, keyPageUp, keyPageDown:
+			m.handleList(key, page)
+			return actionNone
+		default:
+			if len([]rune(key)) == 1 {
+				m.search += key
+			}
+		}
+		m.applyFilters()
+		return actionNone
+	}
+
+	if m.detail {
+		switch key {
+		case "q":
+			return actionQuit
+		case keyEnter, keyEsc:
+			m.detail = false
+		case keyUp, "k":
+			m.detailOffset--
+		case keyDown, "j":
+			m.detailOffset++
+		case keyPageUp:
+			m.detailOffset -= page
+		case keyPageDown:
+			m.detailOffset += page
+		}
+		if m.detailOffset < 0 {
+			m.detailOffset = 0
+		}
+		return actionNone
+	}
+
+	switch key {
+	case "q":
+		return actionQuit
+	case "r":
+		return actionRefresh
+	case "/":
+		m.searching = true
+		m.search = ""
+		m.applyFilters()
+	case keyEsc:
+		m.search = ""
+		m.applyFilters()
+	case "n":
+		m.namespace = next(m.namespaces, m.namespace)
+		m.applyFilters()
+	case "t":
+		m.kind = next(m.kinds, m.kind)
+		m.applyFilters()
+	case "c":
+		m.namespace, m.kind, m.search = "", "", ""
+		m.applyFilters()
+	case keyEnter:
+		if m.selected() != nil {
+			m.detail = true
+		}
+	default:
+		m.handleList(key, page)
+	}
+	return actionNone
+}
+
+func (m *model) handleList(key string, page int) {
+	switch key {
+	case keyUp, "k":
+		m.moveCursor(-1)
+	case keyDown, "j":
+		m.moveCursor(1)
+	case keyPageUp:
+		m.moveCursor(-page)
+	case keyPageDown:
+		m.moveCursor(page)
+	case keyHome, "g":
+		m.moveCursor(-len(m.visible))
+	case keyEnd, "G":
+		m.moveCursor(len(m.visible))
+	}
+}
+
+// layout returns the number of lines of the list and the YAML pane for the given screen height.
+// The remaining lines hold the title, the table header, the separator and the help line.
+func layout(height int) (list, detail int) {
+	content := height - 4
+	if content < 2 {
+		return 1, 0
+	}
+	list = content / 2
+	return list, content - list
+}
+
+// render returns the lines of the screen.
+func (m *model) render(width, height int) []string {
+	listHeight, detailHeight := layout(height)
+
+	// keep the cursor on screen
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+
+	namespace, kind := m.namespace, m.kind
+	if namespace == "" {
+		namespace = "all"
+	}
+	if kind == "" {
+		kind = "all"
+	}
+	title := fmt.Sprintf(" ketall  %d/%d objects  namespace: %s  kind: %s", len(m.visible), len(m.entries), namespace, kind)
+	if m.search != "" {
+		title += "  search: " + m.search
+	}
+
+	lines := []string{reverse + fit(title, width) + reset, bold + fit(m.header, width) + reset}
+	for i := m.offset; i < m.offset+listHeight; i++ {
+		switch {
+		case i >= len(m.visible):
+			lines = append(lines, "")
+		case i == m.cursor && !m.detail:
+			lines = append(lines, reverse+fit(m.entries[m.visible[i]].row, width)+reset)
+		default:
+			lines = append(lines, fit(m.entries[m.visible[i]].row, width))
+		}
+	}
+
+	var yaml []string
+	separator := "── yaml "
+	if o := m.selected(); o != nil {
+		content, ok := m.yamlCache[o]
+		if !ok {
+			var err error
+			if content, err = m.yaml(o); err != nil {
+				content = err.Error()
+			}
+			m.yamlCache[o] = content
+		}
+		yaml = strings.Split(strings.TrimRight(content, "\n"), "\n")
+		separator += m.entries[m.visible[m.cursor]].row + " "
+	}
+	if m.detailOffset > len(yaml)-1 {
+		m.detailOffset = len(yaml) - 1
+	}
+	if m.detailOffset < 0 {
+		m.detailOffset = 0
+	}
+	separator = fit(separator+strings.Repeat("─", width), width)
+	if m.detail {
+		separator = reverse + separator + reset
+	}
+	lines = append(lines, separator)
+	for i := m.detailOffset; i < m.detailOffset+detailHeight; i++ {
+		if i < len(yaml) {
+			lines = append(lines, fit(yaml[i], width))
+		} else {
+			lines = append(lines, "")
+		}
+	}
+
+	switch {
+	case m.searching:
+		lines = append(lines, fit("/"+m.search+"█", width))
+	case m.status != "":
+		lines = append(lines, fit(m.status, width))
+	case m.detail:
+		lines = append(lines, fit(detailHelp, width))
+	default:
+		lines = append(lines, fit(listHelp, width))
+	}
+	return lines[:height]
+}
+
+// fit truncates the line to the given width.
+func fit(line string, width int) string {
+	runes := []rune(line)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return line
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/corneliusweig/ketall/internal/printer"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newUnstructured(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func newTestModel(t *testing.T) *model {
+	m := newModel(func(o runtime.Object) (string, error) {
+		u := o.(*unstructured.Unstructured)
+		return "kind: " + u.GetKind() + "\nname: " + u.GetName() + "\nline3\nline4\n", nil
+	})
+	assert.NoError(t, m.setObjects(&printer.TablePrinter{}, []runtime.Object{
+		newUnstructured("v1", "ConfigMap", "default", "cfg"),
+		newUnstructured("apps/v1", "Deployment", "default", "web"),
+		newUnstructured("apps/v1", "Deployment", "kube-system", "dns"),
+		newUnstructured("v1", "Namespace", "", "default"),
+	}))
+	return m
+}
+
+func rows(m *model) []string {
+	var names []string
+	for _, i := range m.visible {
+		names = append(names, strings.Fields(m.entries[i].row)[0])
+	}
+	return names
+}
+
+func TestModel_Filters(t *testing.T) {
+	m := newTestModel(t)
+	assert.Equal(t, "NAME                 NAMESPACE    AGE", m.header)
+	assert.Equal(t, []string{"configmap/cfg", "deployment.apps/web", "deployment.apps/dns", "namespace/default"}, rows(m))
+	assert.Equal(t, []string{"default", "kube-system"}, m.namespaces)
+	assert.Equal(t, []string{"ConfigMap", "Deployment.apps", "Namespace"}, m.kinds)
+
+	m.handle("n", 10)
+	assert.Equal(t, []string{"configmap/cfg", "deployment.apps/web"}, rows(m))
+	m.handle("t", 10)
+	assert.Equal(t, []string{"configmap/cfg"}, rows(m))
+	m.handle("t", 10)
+	assert.Equal(t, []string{"deployment.apps/web"}, rows(m))
+	m.handle("n", 10)
+	assert.Equal(t, []string{"deployment.apps/dns"}, rows(m))
+	m.handle("n", 10)
+	m.handle("t", 10)
+	m.handle("t", 10)
+	assert.Equal(t, "", m.namespace)
+	assert.Equal(t, "", m.kind)
+	assert.Len(t, m.visible, 4)
+
+	// incremental search, matched against the whole row
+	m.handle("/", 10)
+	for _, key := range []string{"D", "E", "P", "x"} {
+		m.handle(key, 10)
+	}
+	assert.Empty(t, m.visible)
+	m.handle(keyBackspace, 10)
+	assert.Equal(t, []string{"deployment.apps/web", "deployment.apps/dns"}, rows(m))
+	m.handle("q", 10)
+	assert.Equal(t, "DEPq", m.search)
+	m.handle(keyBackspace, 10)
+	m.handle(keyEnter, 10)
+	assert.False(t, m.searching)
+	m.handle("/", 10)
+	m.handle("k", 10)
+	m.handle("u", 10)
+	assert.Equal(t, []string{"deployment.apps/dns"}, rows(m))
+	m.handle(keyEsc, 10)
+	assert.Len(t, m.visible, 4)
+
+	m.handle("n", 10)
+	m.handle("/", 10)
+	m.handle("w", 10)
+	m.handle("e", 10)
+	m.handle(keyEnter, 10)
+	assert.Len(t, m.visible, 1)
+	m.handle("c", 10)
+	assert.Len(t, m.visible, 4)
+}
+
+func TestModel_Navigation(t *testing.T) {
+	m := newTestModel(t)
+	assert.Equal(t, actionNone, m.handle(keyDown, 2))
+	m.handle("j", 2)
+	assert.Equal(t, 2, m.cursor)
+	m.handle(keyPageDown, 2)
+	assert.Equal(t, 3, m.cursor)
+	m.handle(keyPageUp, 2)
+	assert.Equal(t, 1, m.cursor)
+	m.handle("g", 2)
+	assert.Equal(t, 0, m.cursor)
+	m.handle("G", 2)
+	assert.Equal(t, 3, m.cursor)
+
+	// the selection stays within the filtered entries
+	m.handle("n", 2)
+	assert.Equal(t, 1, m.cursor)
+
+	m.handle(keyEnter, 2)
+	assert.True(t, m.detail)
+	m.handle(keyDown, 2)
+	m.handle("j", 2)
+	assert.Equal(t, 2, m.detailOffset)
+	m.handle(keyPageUp, 2)
+	assert.Equal(t, 0, m.detailOffset)
+	m.handle(keyEsc, 2)
+	assert.False(t, m.detail)
+
+	assert.Equal(t, actionRefresh, m.handle("r", 2))
+	assert.Equal(t, actionQuit, m.handle("q", 2))
+	assert.Equal(t, actionQuit, m.handle(keyCtrlC, 2))
+}
+
+func TestModel_Render(t *testing.T) {
+	m := newTestModel(t)
+	m.handle(keyDown, 2)
+
+	lines := m.render(30, 8)
+	assert.Equal(t, []string{
+		reverse + " ketall  4/4 objects  namespac" + reset,
+		bold + "NAME                 NAMESPACE" + reset,
+		"configmap/cfg        default  ",
+		reverse + "deployment.apps/web  default  " + reset,
+		"── yaml deployment.apps/web  d",
+		"kind: Deployment",
+		"name: web",
+		fit(listHelp, 30),
+	}, lines)
+
+	// scrolling keeps the selection visible
+	m.handle("G", 2)
+	lines = m.render(30, 8)
+	assert.Equal(t, "deployment.apps/dns  kube-syst", lines[2])
+	assert.Equal(t, reverse+fit(m.entries[3].row, 30)+reset, lines[3])
+	assert.True(t, strings.HasPrefix(lines[3], reverse+"namespace/default "))
+
+	// the YAML pane is scrolled within its content
+	m.handle(keyEnter, 2)
+	for i := 0; i < 10; i++ {
+		m.handle(keyDown, 2)
+	}
+	lines = m.render(30, 8)
+	assert.Equal(t, []string{"line4", ""}, lines[5:7])
+	assert.Equal(t, fit(detailHelp, 30), lines[7])
+
+	m.handle("/", 2)
+	m.handle(keyEsc, 2)
+	m.detail = false
+	m.handle("/", 2)
+	m.handle("x", 2)
+	lines = m.render(30, 8)
+	assert.Equal(t, []string{"", ""}, lines[5:7])
+	assert.Equal(t, "/x█", lines[7])
+}
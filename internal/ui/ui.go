@@ -0,0 +1,194 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/corneliusweig/ketall/internal/client"
+	"github.com/corneliusweig/ketall/internal/filter"
+	"github.com/corneliusweig/ketall/internal/options"
+	"github.com/corneliusweig/ketall/internal/printer"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// enterScreen switches to the alternate screen and hides the cursor
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	// leaveScreen shows the cursor and switches back to the main screen
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+)
+
+// Run shows the fetched objects in a full-screen terminal user interface, until the user quits.
+func Run(o *options.KetallOptions) error {
+	in, inOK := o.Streams.In.(*os.File)
+	out, outOK := o.Streams.Out.(*os.File)
+	if !inOK || !outOK || !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return errors.New("the user interface needs an interactive terminal")
+	}
+
+	p, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	table, ok := p.(*printer.TablePrinter)
+	if !ok {
+		return fmt.Errorf("the user interface only supports the default and wide table output")
+	}
+	table.Color = false
+	sorter, err := printer.NewSorter(o.PrintFlags.SortBy)
+	if err != nil {
+		return err
+	}
+	yaml, err := yamlRenderer(o.PrintFlags)
+	if err != nil {
+		return err
+	}
+
+	predicates, err := filter.Predicates(o.FilterConfig())
+	if err != nil {
+		return err
+	}
+
+	objects, resourceErrors, err := fetch(o, predicates, sorter)
+	if err != nil {
+		return err
+	}
+	m := newModel(yaml)
+	if err := m.setObjects(table, objects); err != nil {
+		return err
+	}
+	if len(resourceErrors) > 0 {
+		m.status = incompleteStatus(resourceErrors)
+	}
+
+	// klog must not write over the screen, its messages are shown when the user quits
+	logs := &logBuffer{}
+	klog.SetLogger(logr.New(logs))
+	defer logs.WriteTo(o.Streams.ErrOut)
+	defer klog.ClearLogger()
+
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return errors.Wrap(err, "enable raw terminal mode")
+	}
+	defer term.Restore(int(in.Fd()), state)
+	fmt.Fprint(out, enterScreen)
+	defer fmt.Fprint(out, leaveScreen)
+
+	buf := make([]byte, 64)
+	for {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil {
+			return errors.Wrap(err, "get terminal size")
+		}
+		draw(out, m.render(width, height))
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			page, _ := layout(height)
+			switch m.handle(key, page) {
+			case actionQuit:
+				return nil
+			case actionRefresh:
+				m.status = "Refreshing..."
+				draw(out, m.render(width, height))
+				if objects, resourceErrors, err := fetch(o, predicates, sorter); err != nil {
+					m.status = err.Error()
+				} else if err := m.setObjects(table, objects); err != nil {
+					m.status = err.Error()
+				} else if len(resourceErrors) > 0 {
+					m.status = fmt.Sprintf("Refreshed %d objects, %s", len(objects), incompleteStatus(resourceErrors))
+				} else {
+					m.status = fmt.Sprintf("Refreshed %d objects", len(objects))
+				}
+			}
+		}
+	}
+}
+
+// fetch returns the sorted objects which pass the filters, and the resources which could not
+// be fetched.
+func fetch(o *options.KetallOptions, predicates []filter.Predicate, sorter *printer.Sorter) ([]runtime.Object, []client.ResourceError, error) {
+	all, resourceErrors, err := client.GetAllServerResources(o.GenericCliFlags, o.ClientConfig())
+	if err != nil {
+		return nil, resourceErrors, err
+	}
+	filtered, err := filter.ByPredicates(all, predicates...)
+	if err != nil || filtered == nil {
+		return nil, resourceErrors, err
+	}
+	sorted, err := sorter.Sort(filtered)
+	if err != nil {
+		return nil, resourceErrors, err
+	}
+	objects, err := meta.ExtractList(sorted)
+	return objects, resourceErrors, err
+}
+
+func incompleteStatus(resourceErrors []client.ResourceError) string {
+	if len(resourceErrors) == 1 {
+		return "1 resource could not be fetched"
+	}
+	return fmt.Sprintf("%d resources could not be fetched", len(resourceErrors))
+}
+
+// yamlRenderer returns a function which renders an object as YAML, without managed fields
+// and with redacted secrets unless --show-secrets is given.
+func yamlRenderer(flags options.KAPrintFlags) (func(runtime.Object) (string, error), error) {
+	newPrinter := func() (printers.ResourcePrinter, error) {
+		var p printers.ResourcePrinter = &printers.OmitManagedFieldsPrinter{Delegate: &printers.YAMLPrinter{}}
+		if flags.ShowSecrets {
+			return p, nil
+		}
+		return printer.NewRedactAdapterPrinter(printer.NewFlattenListAdapterPrinter(p), flags.Redact)
+	}
+	// check the redaction paths once
+	if _, err := newPrinter(); err != nil {
+		return nil, err
+	}
+
+	return func(o runtime.Object) (string, error) {
+		p, err := newPrinter()
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := p.PrintObj(o, &buf); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}, nil
+}
+
+// draw writes the lines to the terminal, from the top left corner.
+func draw(w io.Writer, lines []string) {
+	fmt.Fprint(w, "\x1b[H"+strings.Join(lines, "\x1b[K\r\n")+"\x1b[K\x1b[J")
+}
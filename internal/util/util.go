@@ -18,7 +18,9 @@ package util
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 func ToV1List(objects []runtime.Object) runtime.Object {
@@ -28,3 +30,26 @@ func ToV1List(objects []runtime.Object) runtime.Object {
 	}
 	return &v1.List{Items: raw}
 }
+
+// FieldManagers returns the sorted names of all field managers which have touched the object.
+func FieldManagers(o metav1.Object) []string {
+	managers := sets.NewString()
+	for _, entry := range o.GetManagedFields() {
+		if entry.Manager != "" {
+			managers.Insert(entry.Manager)
+		}
+	}
+	return managers.List()
+}
+
+// LastUpdate returns the latest time at which any field manager has touched the object, or nil
+// if the managed fields do not record any time.
+func LastUpdate(o metav1.Object) *metav1.Time {
+	var latest *metav1.Time
+	for _, entry := range o.GetManagedFields() {
+		if entry.Time != nil && (latest == nil || latest.Before(entry.Time)) {
+			latest = entry.Time
+		}
+	}
+	return latest
+}
@@ -21,12 +21,9 @@ import (
 
 	"github.com/corneliusweig/ketall/cmd"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
-	"k8s.io/klog/v2"
 )
 
 func main() {
-	if err := cmd.Execute(); err != nil {
-		klog.Error(err)
-		os.Exit(1)
-	}
+	// errors are already printed by the command
+	os.Exit(cmd.ExitCode(cmd.Execute()))
 }
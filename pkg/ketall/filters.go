@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ketall
+
+import (
+	"github.com/corneliusweig/ketall/internal/filter"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Filter tells if an object should be listed. Any function can be used as filter, the
+// constructors in this package provide the filters of the ketall command.
+type Filter func(runtime.Object) bool
+
+// Since selects objects which were created within the given age, such as '1h' or '1d12h'.
+// The age is measured when the filter is applied, so that the filter can be reused.
+func Since(age string) (Filter, error) {
+	p, err := filter.AgePredicate(age)
+	return Filter(p), err
+}
+
+// AnnotationSelector selects objects by an annotation query, which supports '=', '==', '!=',
+// 'key' and '!key'.
+func AnnotationSelector(selector string) (Filter, error) {
+	p, err := filter.AnnotationPredicate(selector)
+	return Filter(p), err
+}
+
+// Unhealthy selects objects which report an unhealthy status, such as failing conditions,
+// a Failed or Pending phase, or missing ready replicas.
+func Unhealthy() Filter {
+	return Filter(filter.UnhealthyPredicate())
+}
+
+// Terminating selects objects which are marked for deletion.
+func Terminating() Filter {
+	return Filter(filter.TerminatingPredicate())
+}
+
+// StuckFor selects objects which are marked for deletion for at least the given age.
+// The age is measured when the filter is applied, so that the filter can be reused.
+func StuckFor(age string) (Filter, error) {
+	p, err := filter.StuckForPredicate(age)
+	return Filter(p), err
+}
+
+// ManagedBy selects objects which were touched by any of the given field managers.
+func ManagedBy(managers ...string) Filter {
+	return Filter(filter.ManagedByPredicate(managers))
+}
+
+// NotManagedBy selects objects which were not touched by any of the given field managers.
+func NotManagedBy(managers ...string) Filter {
+	return Filter(filter.NotManagedByPredicate(managers))
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ketall lists really all resources of a cluster, like the ketall command.
+//
+// A minimal example, which prints all resources of the default namespace:
+//
+//	k, err := ketall.New(ketall.Options{Namespace: "default"})
+//	if err != nil {
+//		return err
+//	}
+//	result, err := k.List(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	p, err := ketall.NewPrinter("")
+//	if err != nil {
+//		return err
+//	}
+//	return result.Print(p, os.Stdout)
+//
+// The package does not depend on any global state, so that several listings can run
+// concurrently, for example against different clusters.
+package ketall
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/corneliusweig/ketall/internal/client"
+	"github.com/corneliusweig/ketall/internal/filter"
+	"github.com/corneliusweig/ketall/internal/printer"
+	"github.com/corneliusweig/ketall/internal/util"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+const (
+	// ScopeCluster only lists cluster level resources
+	ScopeCluster = "cluster"
+	// ScopeNamespace only lists namespaced resources
+	ScopeNamespace = "namespace"
+)
+
+// Options configures which resources are listed.
+type Options struct {
+	// Getter connects to the cluster, defaults to the kubeconfig of the environment
+	Getter genericclioptions.RESTClientGetter
+	// Namespace restricts the namespaced resources to a single namespace, and skips the
+	// cluster level resources unless Scope is ScopeCluster
+	Namespace string
+	// Scope restricts the resources to ScopeCluster or ScopeNamespace
+	Scope string
+	// Selector and FieldSelector are label and field queries, such as 'key1=value1,key2!=value2'
+	Selector      string
+	FieldSelector string
+	// Exclusions are the resource names, short names or kinds which are not listed,
+	// such as 'events' or 'PodMetrics'
+	Exclusions []string
+	// UseCache uses the cached list of server resources
+	UseCache bool
+	// AllowIncomplete lists the resources of all other API groups, when the discovery of
+	// some API groups fails
+	AllowIncomplete bool
+	// MaxInflight restricts the number of concurrent requests, defaults to 64
+	MaxInflight int64
+	// Filters select the listed objects, an object must pass all filters
+	Filters []Filter
+	// SortBy is one of name, namespace, kind, age (youngest first), or a JSONPath expression.
+	// By default, the objects are sorted by API group, kind, namespace and name.
+	SortBy string
+}
+
+// Ketall lists resources with fixed options. It is safe for concurrent use.
+type Ketall struct {
+	options Options
+	sorter  *printer.Sorter
+}
+
+// Result holds the listed objects, together with the resources which could not be listed.
+type Result struct {
+	Objects []runtime.Object
+	Errors  []ResourceError
+}
+
+// ResourceError records that a resource or an API group version could not be listed.
+type ResourceError = client.ResourceError
+
+// New validates the options and returns a Ketall, which lists resources with these options.
+func New(o Options) (*Ketall, error) {
+	switch o.Scope {
+	case "", ScopeCluster, ScopeNamespace:
+	default:
+		return nil, fmt.Errorf("%s is not a valid resource scope (must be one of '%s' or '%s')", o.Scope, ScopeCluster, ScopeNamespace)
+	}
+	sorter, err := printer.NewSorter(o.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	if o.Getter == nil {
+		o.Getter = genericclioptions.NewConfigFlags(true)
+	}
+	return &Ketall{options: o, sorter: sorter}, nil
+}
+
+// List fetches all objects which pass the filters. Resources which cannot be listed, for example
+// due to missing permissions, are reported in the Errors of the result. An error is only
+// returned if no resources could be listed at all.
+//
+// Cancelling ctx stops the listing between requests. Running requests are not aborted yet, so
+// that a slow request delays the cancellation until it finishes.
+func (k *Ketall) List(ctx context.Context) (Result, error) {
+	o := k.options
+	all, resourceErrors, err := client.ListAll(ctx, o.Getter, client.Config{
+		Namespace:       o.Namespace,
+		Scope:           o.Scope,
+		Selector:        o.Selector,
+		FieldSelector:   o.FieldSelector,
+		Exclusions:      o.Exclusions,
+		UseCache:        o.UseCache,
+		AllowIncomplete: o.AllowIncomplete,
+		MaxInflight:     o.MaxInflight,
+	})
+	result := Result{Errors: resourceErrors}
+	if err != nil {
+		return result, err
+	}
+
+	predicates := make([]filter.Predicate, len(o.Filters))
+	for i, f := range o.Filters {
+		predicates[i] = f
+	}
+	filtered, err := filter.ByPredicates(all, predicates...)
+	if err != nil || filtered == nil {
+		return result, err
+	}
+
+	sorted, err := k.sorter.Sort(filtered)
+	if err != nil {
+		return result, err
+	}
+	result.Objects, err = meta.ExtractList(sorted)
+	return result, err
+}
+
+// Complete tells if all resources could be listed.
+func (r Result) Complete() bool {
+	return len(r.Errors) == 0
+}
+
+// List returns the objects as a v1 List.
+func (r Result) List() runtime.Object {
+	return util.ToV1List(r.Objects)
+}
+
+// Print prints all objects with the given printer. The printer receives the objects as a
+// single v1 List, use NewPrinter for the printers of the ketall command.
+func (r Result) Print(p printers.ResourcePrinter, w io.Writer) error {
+	return p.PrintObj(r.List(), w)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ketall
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newObject(kind, name string, created time.Time) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind(kind)
+	u.SetNamespace("default")
+	u.SetName(name)
+	u.SetCreationTimestamp(metav1.NewTime(created))
+	return u
+}
+
+func TestNew(t *testing.T) {
+	k, err := New(Options{Scope: ScopeNamespace, SortBy: "age"})
+	assert.NoError(t, err)
+	assert.NotNil(t, k.options.Getter)
+
+	_, err = New(Options{Scope: "everything"})
+	assert.Error(t, err)
+
+	_, err = New(Options{SortBy: "{.metadata"})
+	assert.Error(t, err)
+}
+
+func TestFilters(t *testing.T) {
+	now := time.Now()
+	young := newObject("ConfigMap", "young", now.Add(-time.Minute))
+	old := newObject("ConfigMap", "old", now.Add(-48*time.Hour))
+	old.SetAnnotations(map[string]string{"team": "a"})
+	old.SetDeletionTimestamp(&metav1.Time{Time: now.Add(-time.Hour)})
+	old.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "helm"}})
+
+	since, err := Since("1h")
+	assert.NoError(t, err)
+	assert.True(t, since(young))
+	assert.False(t, since(old))
+
+	annotated, err := AnnotationSelector("team=a")
+	assert.NoError(t, err)
+	assert.False(t, annotated(young))
+	assert.True(t, annotated(old))
+
+	stuck, err := StuckFor("10m")
+	assert.NoError(t, err)
+	assert.False(t, stuck(young))
+	assert.True(t, stuck(old))
+
+	assert.False(t, Terminating()(young))
+	assert.True(t, Terminating()(old))
+	assert.True(t, ManagedBy("helm")(old))
+	assert.False(t, NotManagedBy("helm")(old))
+	assert.True(t, NotManagedBy("helm")(young))
+
+	_, err = Since("yesterday")
+	assert.Error(t, err)
+	_, err = StuckFor("a while")
+	assert.Error(t, err)
+}
+
+func TestResult(t *testing.T) {
+	r := Result{Objects: []runtime.Object{
+		newObject("ConfigMap", "cfg", time.Now()),
+		newObject("Secret", "token", time.Now()),
+	}}
+	assert.True(t, r.Complete())
+
+	p, err := NewPrinter("name")
+	assert.NoError(t, err)
+	buffer := &bytes.Buffer{}
+	assert.NoError(t, r.Print(p, buffer))
+	assert.Equal(t, "configmap/cfg\nsecret/token\n", buffer.String())
+
+	r.Errors = append(r.Errors, ResourceError{Resource: "pods", Err: errors.New("forbidden")})
+	assert.False(t, r.Complete())
+
+	_, err = NewPrinter("unknown")
+	assert.Error(t, err)
+}
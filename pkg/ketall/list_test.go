@@ -0,0 +1,176 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ketall
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// newFakeAPIServer serves the discovery of config maps and secrets, lists the config maps, and
+// forbids to list the secrets.
+func newFakeAPIServer() *httptest.Server {
+	now := time.Now().UTC()
+	configMap := func(name, uid string, created time.Time) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":              name,
+				"namespace":         "default",
+				"uid":               uid,
+				"creationTimestamp": created.Format(time.RFC3339),
+			},
+		}
+	}
+	responses := map[string]interface{}{
+		"/api":  metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}, Versions: []string{"v1"}},
+		"/apis": metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList"}},
+		"/api/v1": metav1.APIResourceList{
+			TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList"},
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: []string{"get", "list"}},
+				{Name: "secrets", Namespaced: true, Kind: "Secret", Verbs: []string{"get", "list"}},
+			},
+		},
+		"/api/v1/configmaps": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMapList",
+			"metadata":   map[string]interface{}{"resourceVersion": "1"},
+			"items": []interface{}{
+				configMap("web", "2", now.Add(-time.Minute)),
+				configMap("app", "3", now.Add(-time.Minute)),
+				configMap("old", "1", now.Add(-48*time.Hour)),
+			},
+		},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/secrets" {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(metav1.Status{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+				Status:   metav1.StatusFailure,
+				Reason:   metav1.StatusReasonForbidden,
+				Code:     http.StatusForbidden,
+				Message:  `secrets is forbidden: User "test" cannot list resource "secrets"`,
+			})
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func newTestGetter(t *testing.T, server string) genericclioptions.RESTClientGetter {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	assert.NoError(t, os.WriteFile(kubeconfig, nil, 0600))
+
+	flags := genericclioptions.NewConfigFlags(false)
+	flags.KubeConfig = &kubeconfig
+	flags.APIServer = &server
+	cacheDir := filepath.Join(dir, "cache")
+	flags.CacheDir = &cacheDir
+	return flags
+}
+
+func TestKetall_List(t *testing.T) {
+	server := newFakeAPIServer()
+	defer server.Close()
+
+	since, err := Since("1h")
+	assert.NoError(t, err)
+	k, err := New(Options{
+		Getter:  newTestGetter(t, server.URL),
+		Filters: []Filter{since},
+		SortBy:  "name",
+	})
+	assert.NoError(t, err)
+
+	result, err := k.List(context.Background())
+	assert.NoError(t, err)
+
+	var names []string
+	for _, o := range result.Objects {
+		names = append(names, o.(metav1.Object).GetName())
+	}
+	assert.Equal(t, []string{"app", "web"}, names)
+
+	assert.False(t, result.Complete())
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "secrets", result.Errors[0].Resource)
+		assert.Contains(t, result.Errors[0].Error(), "forbidden")
+	}
+}
+
+func TestKetall_ListCancelled(t *testing.T) {
+	server := newFakeAPIServer()
+	defer server.Close()
+
+	k, err := New(Options{Getter: newTestGetter(t, server.URL)})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = k.List(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestKetall_ListConcurrently(t *testing.T) {
+	server := newFakeAPIServer()
+	defer server.Close()
+
+	k, err := New(Options{Getter: newTestGetter(t, server.URL), SortBy: ".metadata.uid"})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	names := make([][]string, 8)
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := k.List(context.Background())
+			assert.NoError(t, err)
+			for _, o := range result.Objects {
+				names[i] = append(names[i], o.(metav1.Object).GetName())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, got := range names {
+		assert.Equal(t, []string{"old", "web", "app"}, got)
+	}
+}
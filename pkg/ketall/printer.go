@@ -0,0 +1,36 @@
+/*
+Copyright 2019 Cornelius Weig
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ketall
+
+import (
+	"github.com/corneliusweig/ketall/internal/options"
+	"github.com/corneliusweig/ketall/internal/printer"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// NewPrinter returns a printer for one of the output formats of the ketall command, such as
+// "" for the default table, "wide", "yaml", "jsonl", "tree" or "custom-columns=NAME:.metadata.name".
+// The printer expects a list of objects, as given by Result.Print. Secrets are redacted.
+func NewPrinter(output string) (printers.ResourcePrinter, error) {
+	flags := options.NewKAPrintFlags()
+	flags.OutputFormat = &output
+	p, err := flags.ToPrinter()
+	if err != nil {
+		return nil, err
+	}
+	return printer.NewRedactAdapterPrinter(printer.NewListPrinter(p), nil)
+}